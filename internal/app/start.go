@@ -4,6 +4,9 @@ import (
 	_ "embed"
 	"fmt"
 	"strings"
+
+	"github.com/gombrii/aoc/internal/commands"
+	"github.com/gombrii/aoc/internal/config"
 )
 
 const (
@@ -14,9 +17,16 @@ const (
 	opInit    = "init"
 	opCache   = "cache"
 	opClear   = "clear"
+	opExport  = "export"
+	opImport  = "import"
+	opList    = "ls"
+	opPrune   = "prune"
 	opCheck   = "check"
 	opLogin   = "login"
 	opSubmit  = "submit"
+	opProfile = "profile"
+	opWatch   = "watch"
+	opHistory = "history"
 	opVersion = "version"
 	opHelp    = "help"
 )
@@ -31,19 +41,33 @@ var helpText string
 var appendixText string
 
 type Commands interface {
-	Run(year, day, part int, input string) error
+	Run(year, day, part int, input string, opts commands.RunOpts) error
 	Status(year, day, part int, input string) error
 	Lock(year, day, part int, input string) error
 	Unlock(year, day, part int, input string) error
 	GenDay(year, day int) error
 	GenAoc(module string) error
-	Check() error
-	ClearCache() error
+	Check(opts commands.CheckOpts) error
+	ClearCache(opts commands.ClearOpts) error
+	ListCache() error
+	PruneCache() error
+	ExportCache(session bool) error
+	ImportCache(src string) error
 	Login(session string) error
-	Submit() error
+	Submit(opts commands.SubmitOpts) error
+	Profile(year, day, part int, input string, opts commands.ProfileOpts) error
+	Watch(year, day, part int, input string, opts commands.RunOpts) error
+	History(year, day, part int, input string, last int) error
+	SetJSON(on bool)
 }
 
 func Start(cmd Commands, args ...string) error {
+	args, json, err := outputFormat(args)
+	if err != nil {
+		return err
+	}
+	cmd.SetJSON(json)
+
 	if len(args) == 0 {
 		fmt.Println(strings.ReplaceAll(usageText, "{{year}}", fmt.Sprint(defaultYear())))
 		return nil
@@ -73,14 +97,30 @@ func Start(cmd Commands, args ...string) error {
 		return submit(cmd, args[1:]...)
 	case opCheck:
 		return check(cmd, args[1:]...)
+	case opProfile:
+		return profile(cmd, args[1:]...)
+	case opWatch:
+		return watch(cmd, args[1:]...)
+	case opHistory:
+		return history(cmd, args[1:]...)
 	case opCache:
 		if len(args) < 2 {
 			return fmt.Errorf("unknown command: %s", args[0])
 		}
-		if args[1] != opClear {
+		switch args[1] {
+		case opClear:
+			return cacheClear(cmd, args[2:]...)
+		case opExport:
+			return cacheExport(cmd, args[2:]...)
+		case opImport:
+			return cacheImport(cmd, args[2:]...)
+		case opList:
+			return cacheList(cmd, args[2:]...)
+		case opPrune:
+			return cachePrune(cmd, args[2:]...)
+		default:
 			return fmt.Errorf("unknown command: %s", args[1])
 		}
-		return cacheClear(cmd, args[2:]...)
 	default:
 		return fmt.Errorf("unknown command: %s", args[0])
 	}
@@ -91,16 +131,29 @@ func run(cmd Commands, args ...string) error {
 
 	year := fs.Int("y", defaultYear(), "year of the puzzle to run")
 	day := fs.Int("d", 0, "day of the puzzle")
-	part := fs.Int("p", 0, "which part of the puzzle to run")
-	input := fs.String("i", "", `input file to feed the puzzle. Mutually exclusive with -t (default "input.txt")`)
+	part := fs.Int("p", 0, "which part of the puzzle to run (default both parts)")
+	input := fs.String("i", "", `input file of the day to feed the puzzle, a path to any file, "-" for stdin or "all" to run every input file of the day. Mutually exclusive with -t (default "input.txt")`)
 	test := fs.Bool("t", false, `shorthand for "-i test.txt". Mutually exclusive with -i`)
-
+	bench := fs.Bool("bench", false, "benchmark the puzzle after running it")
+	benchTime := fs.String("benchtime", "", `time budget, eg. "2s", or number of iterations, eg. "100x", of a benchmark. Implies -bench (default "1s")`)
+	timeout := fs.Duration("timeout", 0, "kill the puzzle if it runs longer than this (default from aoc.json, otherwise none)")
+	cpuProfile := fs.String("cpuprofile", "", "write a CPU profile of the puzzle to this file. Requires -p")
+	memProfile := fs.String("memprofile", "", "write an allocation profile of the puzzle to this file. Requires -p")
+	trace := fs.String("trace", "", "write an execution trace of the puzzle to this file. Requires -p")
+	race := fs.Bool("race", false, "build the puzzle with the race detector (default from aoc.json)")
+	tags := fs.String("tags", "", "comma-separated build tags to build the puzzle with (default from aoc.json)")
+	gcflags := fs.String("gcflags", "", "arguments to pass on to the compiler, eg. \"-N -l\" (default from aoc.json)")
+	pgo := fs.String("pgo", "", "CPU profile to optimize the build of the puzzle with, or \"off\" (default from aoc.json)")
+	submit := fs.Bool("submit", false, "submit the result of the puzzle after running it with input.txt. Requires -p and login")
+
+	// With -p optional, flags after a stray argument would otherwise be dropped without notice.
 	if err := parse(fs, buf, args,
+		noArgs(fs),
 		required(fs, "y", year),
 		required(fs, "d", day),
-		required(fs, "p", part),
 		mutuallyExclusive(fs, "i", input, "t", test),
-		inRange(fs, "p", part, 1, 2),
+		ifProvided(fs, "p", inRange(fs, "p", part, 1, 2)),
+		ifProvided(fs, "submit", required(fs, "p", part)),
 	); err != nil {
 		return err
 	}
@@ -114,7 +167,16 @@ func run(cmd Commands, args ...string) error {
 		input = &i
 	}
 
-	return cmd.Run(*year, *day, *part, *input)
+	return cmd.Run(*year, *day, *part, *input, commands.RunOpts{
+		Bench:      *bench || isSet(benchTime),
+		BenchTime:  *benchTime,
+		Timeout:    *timeout,
+		CPUProfile: *cpuProfile,
+		MemProfile: *memProfile,
+		Trace:      *trace,
+		Build:      buildOpts(fs, race, tags, gcflags, pgo),
+		Submit:     *submit,
+	})
 }
 func initialize(cmd Commands, args ...string) error {
 	fs, buf := flagSet(opInit)
@@ -157,40 +219,255 @@ func cacheClear(cmd Commands, args ...string) error {
 	fs, buf := flagSet(opCache + " " + opClear)
 	fs.Usage = func() {
 		fmt.Println("Usage of cache clear:")
-		fmt.Println("Clear aoc cache including all puzzle results, durations and login token")
+		fmt.Println("Clear aoc cache including all puzzle results, durations and login token, or only some puzzles")
+		fs.PrintDefaults()
+	}
+
+	year := fs.Int("y", 0, "only clear puzzles of this year")
+	day := fs.Int("d", 0, "only clear puzzles of this day")
+	part := fs.Int("p", 0, "only clear this part of puzzles")
+	runners := fs.Bool("runners", false, "only clear runners, keeping results and locks")
+
+	if err := parse(fs, buf, args,
+		ifProvided(fs, "d", inRange(fs, "d", day, 1, 25)),
+		ifProvided(fs, "p", inRange(fs, "p", part, 1, 2)),
+	); err != nil {
+		return err
 	}
 
+	return cmd.ClearCache(commands.ClearOpts{Year: *year, Day: *day, Part: *part, Runners: *runners})
+}
+func cacheList(cmd Commands, args ...string) error {
+	fs, buf := flagSet(opCache + " " + opList)
+
 	if err := parse(fs, buf, args); err != nil {
 		return err
 	}
 
-	return cmd.ClearCache()
+	return cmd.ListCache()
+}
+func cachePrune(cmd Commands, args ...string) error {
+	fs, buf := flagSet(opCache + " " + opPrune)
+
+	if err := parse(fs, buf, args); err != nil {
+		return err
+	}
+
+	return cmd.PruneCache()
+}
+func cacheExport(cmd Commands, args ...string) error {
+	fs, buf := flagSet(opCache + " " + opExport)
+
+	session := fs.Bool("session", false, "include the session token of the logged in user")
+
+	if err := parse(fs, buf, args); err != nil {
+		return err
+	}
+
+	return cmd.ExportCache(*session)
+}
+func cacheImport(cmd Commands, args ...string) error {
+	fs, buf := flagSet(opCache + " " + opImport)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage of cache import:")
+		fmt.Fprintln(fs.Output(), `  aoc cache import {FILE|-}`)
+	}
+
+	// The archive comes first, "-" being stdin.
+	if len(args) == 0 || (strings.HasPrefix(args[0], "-") && args[0] != "-") {
+		fmt.Fprintln(fs.Output(), "archive to import required")
+		fs.Usage()
+		return fmt.Errorf("%w%s", ErrInput, buf.String())
+	}
+	src := args[0]
+
+	if err := parse(fs, buf, args[1:], noArgs(fs)); err != nil {
+		return err
+	}
+
+	return cmd.ImportCache(src)
 }
 func check(cmd Commands, args ...string) error {
 	fs, buf := flagSet(opCheck)
 	fs.Usage = func() {
 		fmt.Println("Usage of check:")
 		fmt.Println("Run and verify correct results from all locked solutions")
+		fs.PrintDefaults()
 	}
 
-	if err := parse(fs, buf, args); err != nil {
+	timeout := fs.Duration("timeout", 0, "kill a puzzle if it runs longer than this (default from aoc.json, otherwise none)")
+	race := fs.Bool("race", false, "build puzzles with the race detector (default from aoc.json)")
+	tags := fs.String("tags", "", "comma-separated build tags to build puzzles with (default from aoc.json)")
+	gcflags := fs.String("gcflags", "", "arguments to pass on to the compiler, eg. \"-N -l\" (default from aoc.json)")
+	pgo := fs.String("pgo", "", "CPU profile to optimize the builds of puzzles with, or \"off\" (default from aoc.json)")
+	year := fs.Int("y", 0, "only check puzzles of this year")
+	var days dayRange
+	fs.Var(&days, "d", `only check puzzles of this day, or range of days, eg. "1-10"`)
+	part := fs.Int("p", 0, "only check this part of puzzles")
+	match := fs.String("match", "", `only check puzzles with names matching any of these comma-separated patterns, eg. "2024/day1*/part2"`)
+	failed := fs.Bool("failed", false, "only check puzzles that didn't pass the last check")
+	jobs := fs.Int("j", 0, "number of puzzles to build and run at the same time (default GOMAXPROCS)")
+	serial := fs.Bool("serial-timing", false, "run one puzzle at a time, so that durations compare fairly to the best ones")
+	var slow config.Threshold
+	fs.Var(&slow, "slow", `fail puzzles slower than their best by more than this, eg. "20%" or "5ms" (default from aoc.json, otherwise never)`)
+
+	if err := parse(fs, buf, args,
+		ifProvided(fs, "p", inRange(fs, "p", part, 1, 2)),
+	); err != nil {
 		return err
 	}
 
-	return cmd.Check()
+	var patterns []string
+	if isSet(match) {
+		patterns = strings.Split(*match, ",")
+	}
+
+	return cmd.Check(commands.CheckOpts{
+		Timeout:      *timeout,
+		Build:        buildOpts(fs, race, tags, gcflags, pgo),
+		Year:         *year,
+		FromDay:      days.from,
+		ToDay:        days.to,
+		Part:         *part,
+		Match:        patterns,
+		Failed:       *failed,
+		Jobs:         *jobs,
+		SerialTiming: *serial,
+		Slow:         slow,
+	})
 }
 func submit(cmd Commands, args ...string) error {
 	fs, buf := flagSet(opSubmit)
 	fs.Usage = func() {
 		fmt.Println("Usage of submit:")
-		fmt.Println("Submit last run result. Requires login.")
+		fmt.Println("Submit the result of the last run puzzle, or of the puzzle given. Requires login.")
+		fs.PrintDefaults()
 	}
 
-	if err := parse(fs, buf, args); err != nil {
+	year := fs.Int("y", defaultYear(), "year of the puzzle")
+	day := fs.Int("d", 0, "day of the puzzle (default the last run puzzle)")
+	part := fs.Int("p", 0, "part of the puzzle")
+	answer := fs.String("a", "", "answer to submit instead of the result of the puzzle")
+	yes := fs.Bool("yes", false, "submit without asking for confirmation")
+
+	if err := parse(fs, buf, args,
+		ifProvided(fs, "d", inRange(fs, "d", day, 1, 25)),
+		ifProvided(fs, "p", inRange(fs, "p", part, 1, 2)),
+		ifProvided(fs, "d", required(fs, "p", part)),
+		ifProvided(fs, "p", required(fs, "d", day)),
+		ifProvided(fs, "a", required(fs, "d", day)),
+		ifProvided(fs, "y", required(fs, "d", day)),
+	); err != nil {
+		return err
+	}
+
+	opts := commands.SubmitOpts{Answer: *answer, Yes: *yes}
+	if isSet(day) {
+		opts.Year, opts.Day, opts.Part = *year, *day, *part
+	}
+
+	return cmd.Submit(opts)
+}
+func profile(cmd Commands, args ...string) error {
+	fs, buf := flagSet(opProfile)
+
+	year := fs.Int("y", defaultYear(), "year of the puzzle")
+	day := fs.Int("d", 0, "day of the puzzle")
+	part := fs.Int("p", 0, "part of the puzzle")
+	input := fs.String("i", "", `input file the puzzle was profiled with. Mutually exclusive with -t (default "input.txt")`)
+	test := fs.Bool("t", false, `shorthand for "-i test.txt". Mutually exclusive with -i`)
+	mem := fs.Bool("mem", false, "open the allocation profile instead of the CPU profile. Mutually exclusive with -trace")
+	trace := fs.Bool("trace", false, "open the execution trace instead of the CPU profile. Mutually exclusive with -mem")
+
+	if err := parse(fs, buf, args,
+		required(fs, "y", year),
+		required(fs, "d", day),
+		required(fs, "p", part),
+		inRange(fs, "p", part, 1, 2),
+		mutuallyExclusive(fs, "i", input, "t", test),
+		mutuallyExclusive(fs, "mem", mem, "trace", trace),
+	); err != nil {
 		return err
 	}
 
-	return cmd.Submit()
+	switch {
+	case isSet(test):
+		i := "test.txt"
+		input = &i
+	case !isSet(input):
+		i := "input.txt"
+		input = &i
+	}
+
+	return cmd.Profile(*year, *day, *part, *input, commands.ProfileOpts{Mem: *mem, Trace: *trace})
+}
+func history(cmd Commands, args ...string) error {
+	fs, buf := flagSet(opHistory)
+
+	year := fs.Int("y", defaultYear(), "year of the puzzle")
+	day := fs.Int("d", 0, "day of the puzzle")
+	part := fs.Int("p", 0, "part of the puzzle")
+	input := fs.String("i", "", `input file the puzzle was run with. Mutually exclusive with -t (default "input.txt")`)
+	test := fs.Bool("t", false, `shorthand for "-i test.txt". Mutually exclusive with -i`)
+	last := fs.Int("n", 20, "number of runs to show, 0 for all")
+
+	if err := parse(fs, buf, args,
+		required(fs, "y", year),
+		required(fs, "d", day),
+		required(fs, "p", part),
+		inRange(fs, "p", part, 1, 2),
+		mutuallyExclusive(fs, "i", input, "t", test),
+	); err != nil {
+		return err
+	}
+
+	switch {
+	case isSet(test):
+		i := "test.txt"
+		input = &i
+	case !isSet(input):
+		i := "input.txt"
+		input = &i
+	}
+
+	return cmd.History(*year, *day, *part, *input, *last)
+}
+func watch(cmd Commands, args ...string) error {
+	fs, buf := flagSet(opWatch)
+
+	year := fs.Int("y", defaultYear(), "year of the puzzle to watch")
+	day := fs.Int("d", 0, "day of the puzzle")
+	part := fs.Int("p", 0, "which part of the puzzle to watch (default both parts)")
+	input := fs.String("i", "", `input file of the day to feed the puzzle, or a path to any file. Mutually exclusive with -t (default "input.txt")`)
+	test := fs.Bool("t", false, `shorthand for "-i test.txt". Mutually exclusive with -i`)
+	timeout := fs.Duration("timeout", 0, "kill the puzzle if it runs longer than this (default from aoc.json, otherwise none)")
+	race := fs.Bool("race", false, "build the puzzle with the race detector (default from aoc.json)")
+	tags := fs.String("tags", "", "comma-separated build tags to build the puzzle with (default from aoc.json)")
+	gcflags := fs.String("gcflags", "", "arguments to pass on to the compiler, eg. \"-N -l\" (default from aoc.json)")
+	pgo := fs.String("pgo", "", "CPU profile to optimize the build of the puzzle with, or \"off\" (default from aoc.json)")
+
+	if err := parse(fs, buf, args,
+		required(fs, "y", year),
+		required(fs, "d", day),
+		mutuallyExclusive(fs, "i", input, "t", test),
+		ifProvided(fs, "p", inRange(fs, "p", part, 1, 2)),
+	); err != nil {
+		return err
+	}
+
+	switch {
+	case isSet(test):
+		i := "test.txt"
+		input = &i
+	case !isSet(input):
+		i := "input.txt"
+		input = &i
+	}
+
+	return cmd.Watch(*year, *day, *part, *input, commands.RunOpts{
+		Timeout: *timeout,
+		Build:   buildOpts(fs, race, tags, gcflags, pgo),
+	})
 }
 func help(args ...string) error {
 	fs, buf := flagSet(opHelp)
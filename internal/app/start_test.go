@@ -1,12 +1,16 @@
 package app_test
 
 import (
+	"reflect"
 	"runtime"
 	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gombrii/aoc/internal/app"
+	cmds "github.com/gombrii/aoc/internal/commands"
+	"github.com/gombrii/aoc/internal/config"
 )
 
 type record map[string][]any
@@ -26,8 +30,8 @@ type commands struct {
 	record record
 }
 
-func (c *commands) Run(year, day, part int, input string) error {
-	c.record.save(year, day, part, input)
+func (c *commands) Run(year, day, part int, input string, opts cmds.RunOpts) error {
+	c.record.save(year, day, part, input, opts)
 	return nil
 }
 func (c *commands) Status(year, day, part int, input string) error {
@@ -50,20 +54,51 @@ func (c *commands) GenAoc(module string) error {
 	c.record.save(module)
 	return nil
 }
-func (c *commands) Check() error {
+func (c *commands) Check(opts cmds.CheckOpts) error {
+	c.record.save(opts)
+	return nil
+}
+func (c *commands) ClearCache(opts cmds.ClearOpts) error {
+	c.record.save(opts)
+	return nil
+}
+func (c *commands) ListCache() error {
 	c.record.save()
 	return nil
 }
-func (c *commands) ClearCache() error {
+func (c *commands) PruneCache() error {
 	c.record.save()
 	return nil
 }
+func (c *commands) ExportCache(session bool) error {
+	c.record.save(session)
+	return nil
+}
+func (c *commands) ImportCache(src string) error {
+	c.record.save(src)
+	return nil
+}
 func (c *commands) Login(session string) error {
 	c.record.save(session)
 	return nil
 }
-func (c *commands) Submit() error {
-	c.record.save()
+func (c *commands) Submit(opts cmds.SubmitOpts) error {
+	c.record.save(opts)
+	return nil
+}
+func (c *commands) Watch(year, day, part int, input string, opts cmds.RunOpts) error {
+	c.record.save(year, day, part, input, opts)
+	return nil
+}
+func (c *commands) SetJSON(on bool) {
+	c.record.save(on)
+}
+func (c *commands) History(year, day, part int, input string, last int) error {
+	c.record.save(year, day, part, input, last)
+	return nil
+}
+func (c *commands) Profile(year, day, part int, input string, opts cmds.ProfileOpts) error {
+	c.record.save(year, day, part, input, opts)
 	return nil
 }
 
@@ -76,27 +111,107 @@ func TestSuccessful(t *testing.T) {
 		"Run": {
 			args:   "-d 1 -p 1",
 			called: "Run",
-			with:   []any{2025, 1, 1, "input.txt"},
+			with:   []any{2025, 1, 1, "input.txt", cmds.RunOpts{}},
 		},
 		"Run other year": {
 			args:   "-d 1 -y 2023 -p 1",
 			called: "Run",
-			with:   []any{2023, 1, 1, "input.txt"},
+			with:   []any{2023, 1, 1, "input.txt", cmds.RunOpts{}},
 		},
 		"Run other input": {
 			args:   "-d 1 -i test2.txt -p 1",
 			called: "Run",
-			with:   []any{2025, 1, 1, "test2.txt"},
+			with:   []any{2025, 1, 1, "test2.txt", cmds.RunOpts{}},
+		},
+		"Run all inputs": {
+			args:   "-d 1 -i all -p 1",
+			called: "Run",
+			with:   []any{2025, 1, 1, "all", cmds.RunOpts{}},
+		},
+		"Run stdin": {
+			args:   "-d 1 -p 1 -i -",
+			called: "Run",
+			with:   []any{2025, 1, 1, "-", cmds.RunOpts{}},
+		},
+		"Run path": {
+			args:   "-d 1 -p 1 -i ../stress/big.txt",
+			called: "Run",
+			with:   []any{2025, 1, 1, "../stress/big.txt", cmds.RunOpts{}},
 		},
 		"Run other year and input": {
 			args:   "-d 1 -i test2.txt -p 1 -y 2023",
 			called: "Run",
-			with:   []any{2023, 1, 1, "test2.txt"},
+			with:   []any{2023, 1, 1, "test2.txt", cmds.RunOpts{}},
+		},
+		"Run both parts": {
+			args:   "-d 1",
+			called: "Run",
+			with:   []any{2025, 1, 0, "input.txt", cmds.RunOpts{}},
+		},
+		"Run both parts other year and test": {
+			args:   "-d 1 -t -y 2023",
+			called: "Run",
+			with:   []any{2023, 1, 0, "test.txt", cmds.RunOpts{}},
 		},
 		"Run other year and test": {
 			args:   "-d 1 -t -p 1 -y 2023",
 			called: "Run",
-			with:   []any{2023, 1, 1, "test.txt"},
+			with:   []any{2023, 1, 1, "test.txt", cmds.RunOpts{}},
+		},
+		"Run bench": {
+			args:   "-d 1 -p 1 -bench",
+			called: "Run",
+			with:   []any{2025, 1, 1, "input.txt", cmds.RunOpts{Bench: true}},
+		},
+		"Run benchtime": {
+			args:   "-d 1 -p 1 --benchtime 100x",
+			called: "Run",
+			with:   []any{2025, 1, 1, "input.txt", cmds.RunOpts{Bench: true, BenchTime: "100x"}},
+		},
+		"Run with timeout": {
+			args:   "-d 1 -p 1 -timeout 1m",
+			called: "Run",
+			with:   []any{2025, 1, 1, "input.txt", cmds.RunOpts{Timeout: time.Minute}},
+		},
+		"Run with profiles": {
+			args:   "-d 1 -p 1 -cpuprofile cpu.pprof -memprofile mem.pprof -trace trace.out",
+			called: "Run",
+			with:   []any{2025, 1, 1, "input.txt", cmds.RunOpts{CPUProfile: "cpu.pprof", MemProfile: "mem.pprof", Trace: "trace.out"}},
+		},
+		"Run with build flags": {
+			args:   "-d 1 -p 1 -race -tags foo,bar -gcflags all=-N -pgo off",
+			called: "Run",
+			with:   []any{2025, 1, 1, "input.txt", cmds.RunOpts{Build: cmds.BuildOpts{Race: true, Tags: "foo,bar", GCFlags: "all=-N", PGO: "off", Provided: []string{"race", "tags", "gcflags", "pgo"}}}},
+		},
+		"Run with build flags cleared": {
+			args:   "-d 1 -p 1 -race=false -tags=",
+			called: "Run",
+			with:   []any{2025, 1, 1, "input.txt", cmds.RunOpts{Build: cmds.BuildOpts{Provided: []string{"race", "tags"}}}},
+		},
+		"Watch": {
+			args:   "watch -d 1 -p 1 -t",
+			called: "Watch",
+			with:   []any{2025, 1, 1, "test.txt", cmds.RunOpts{}},
+		},
+		"Watch both parts other year": {
+			args:   "watch -d 1 -y 2023 -timeout 5s",
+			called: "Watch",
+			with:   []any{2023, 1, 0, "input.txt", cmds.RunOpts{Timeout: 5 * time.Second}},
+		},
+		"Profile": {
+			args:   "profile -d 1 -p 2",
+			called: "Profile",
+			with:   []any{2025, 1, 2, "input.txt", cmds.ProfileOpts{}},
+		},
+		"Profile mem other year and test": {
+			args:   "profile -d 1 -p 2 -y 2023 -t -mem",
+			called: "Profile",
+			with:   []any{2023, 1, 2, "test.txt", cmds.ProfileOpts{Mem: true}},
+		},
+		"Profile trace": {
+			args:   "profile -d 1 -p 2 -trace",
+			called: "Profile",
+			with:   []any{2025, 1, 2, "input.txt", cmds.ProfileOpts{Trace: true}},
 		},
 		"Status": {
 			args:   "status -d 1 -p 1",
@@ -146,13 +261,108 @@ func TestSuccessful(t *testing.T) {
 		"Check": {
 			args:   "check",
 			called: "Check",
-			with:   []any{},
+			with:   []any{cmds.CheckOpts{}},
+		},
+		"Check with timeout": {
+			args:   "check -timeout 10s",
+			called: "Check",
+			with:   []any{cmds.CheckOpts{Timeout: 10 * time.Second}},
+		},
+		"Check with build flags": {
+			args:   "check -race -tags foo",
+			called: "Check",
+			with:   []any{cmds.CheckOpts{Build: cmds.BuildOpts{Race: true, Tags: "foo", Provided: []string{"race", "tags"}}}},
+		},
+		"Check year and part": {
+			args:   "check -y 2024 -p 2",
+			called: "Check",
+			with:   []any{cmds.CheckOpts{Year: 2024, Part: 2}},
+		},
+		"Check day": {
+			args:   "check -d 5",
+			called: "Check",
+			with:   []any{cmds.CheckOpts{FromDay: 5, ToDay: 5}},
+		},
+		"Check day range": {
+			args:   "check -d 1-10",
+			called: "Check",
+			with:   []any{cmds.CheckOpts{FromDay: 1, ToDay: 10}},
+		},
+		"Check failed": {
+			args:   "check -failed",
+			called: "Check",
+			with:   []any{cmds.CheckOpts{Failed: true}},
+		},
+		"Check jobs and serial timing": {
+			args:   "check -j 4 --serial-timing",
+			called: "Check",
+			with:   []any{cmds.CheckOpts{Jobs: 4, SerialTiming: true}},
+		},
+		"Check slow percentage": {
+			args:   "check -slow 20%",
+			called: "Check",
+			with:   []any{cmds.CheckOpts{Slow: config.Threshold{Percent: 20}}},
+		},
+		"Check slow duration": {
+			args:   "check -slow 5ms",
+			called: "Check",
+			with:   []any{cmds.CheckOpts{Slow: config.Threshold{Abs: 5 * time.Millisecond}}},
 		},
 		"ClearCache": {
 			args:   "cache clear",
 			called: "ClearCache",
+			with:   []any{cmds.ClearOpts{}},
+		},
+		"ClearCache of puzzle": {
+			args:   "cache clear -y 2023 -d 5 -p 1",
+			called: "ClearCache",
+			with:   []any{cmds.ClearOpts{Year: 2023, Day: 5, Part: 1}},
+		},
+		"ClearCache runners": {
+			args:   "cache clear --runners",
+			called: "ClearCache",
+			with:   []any{cmds.ClearOpts{Runners: true}},
+		},
+		"ListCache": {
+			args:   "cache ls",
+			called: "ListCache",
+			with:   []any{},
+		},
+		"PruneCache": {
+			args:   "cache prune",
+			called: "PruneCache",
 			with:   []any{},
 		},
+		"History": {
+			args:   "history -d 1 -p 2",
+			called: "History",
+			with:   []any{2025, 1, 2, "input.txt", 20},
+		},
+		"History of test input": {
+			args:   "history -d 1 -p 1 -t -n 0",
+			called: "History",
+			with:   []any{2025, 1, 1, "test.txt", 0},
+		},
+		"ExportCache": {
+			args:   "cache export",
+			called: "ExportCache",
+			with:   []any{false},
+		},
+		"ExportCache with session": {
+			args:   "cache export -session",
+			called: "ExportCache",
+			with:   []any{true},
+		},
+		"ImportCache": {
+			args:   "cache import state.tar.gz",
+			called: "ImportCache",
+			with:   []any{"state.tar.gz"},
+		},
+		"ImportCache from stdin": {
+			args:   "cache import -",
+			called: "ImportCache",
+			with:   []any{"-"},
+		},
 		"Login": {
 			args:   "login -s abc123",
 			called: "Login",
@@ -161,7 +371,52 @@ func TestSuccessful(t *testing.T) {
 		"Submit": {
 			args:   "submit",
 			called: "Submit",
-			with:   []any{},
+			with:   []any{cmds.SubmitOpts{}},
+		},
+		"Submit puzzle": {
+			args:   "submit -d 3 -p 1",
+			called: "Submit",
+			with:   []any{cmds.SubmitOpts{Year: 2025, Day: 3, Part: 1}},
+		},
+		"Submit answer": {
+			args:   "submit -y 2024 -d 3 -p 2 -a 1234 --yes",
+			called: "Submit",
+			with:   []any{cmds.SubmitOpts{Year: 2024, Day: 3, Part: 2, Answer: "1234", Yes: true}},
+		},
+		"Text output": {
+			args:   "status -d 1 -p 1",
+			called: "SetJSON",
+			with:   []any{false},
+		},
+		"JSON output": {
+			args:   "--json status -d 1 -p 1",
+			called: "SetJSON",
+			with:   []any{true},
+		},
+		"JSON output after command": {
+			args:   "check -json",
+			called: "SetJSON",
+			with:   []any{true},
+		},
+		"JSON output format": {
+			args:   "-d 1 -p 1 -o json",
+			called: "SetJSON",
+			with:   []any{true},
+		},
+		"JSON output format with equals": {
+			args:   "-o=json -d 1 -p 1",
+			called: "SetJSON",
+			with:   []any{true},
+		},
+		"Run and submit": {
+			args:   "-d 1 -p 2 --submit",
+			called: "Run",
+			with:   []any{2025, 1, 2, "input.txt", cmds.RunOpts{Submit: true}},
+		},
+		"Run with JSON output": {
+			args:   "--json -d 1 -p 1",
+			called: "Run",
+			with:   []any{2025, 1, 1, "input.txt", cmds.RunOpts{}},
 		},
 	} {
 		t.Run(name, func(t *testing.T) {
@@ -176,7 +431,7 @@ func TestSuccessful(t *testing.T) {
 			if !ok {
 				t.Fatal(params.called, "was not called")
 			}
-			if !slices.Equal(args, params.with) {
+			if !slices.EqualFunc(args, params.with, func(a, b any) bool { return reflect.DeepEqual(a, b) }) {
 				t.Error("\nGot:", args, "\nWant:", params.with)
 			}
 		})
@@ -190,9 +445,6 @@ func TestError(t *testing.T) {
 		"Run missing part": {
 			args: "-p 1",
 		},
-		"Run missing day": {
-			args: "-d 1",
-		},
 		"Run with module": {
 			args: "-d 1 -p 1 -m mymodule",
 		},
@@ -208,6 +460,36 @@ func TestError(t *testing.T) {
 		"Run with verbose": {
 			args: "-d 1 -p 1 -v",
 		},
+		"Check part 3": {
+			args: "check -p 3",
+		},
+		"Check reversed day range": {
+			args: "check -d 10-1",
+		},
+		"Check day 26": {
+			args: "check -d 26",
+		},
+		"Check day range with garbage": {
+			args: "check -d 1-x",
+		},
+		"Unknown output format": {
+			args: "-d 1 -p 1 -o xml",
+		},
+		"Output format missing": {
+			args: "-d 1 -p 1 -o",
+		},
+		"Watch missing day": {
+			args: "watch -p 1",
+		},
+		"Watch input and test": {
+			args: "watch -d 1 -i test2.txt -t",
+		},
+		"Profile missing part": {
+			args: "profile -d 1",
+		},
+		"Profile mem and trace": {
+			args: "profile -d 1 -p 1 -mem -trace",
+		},
 		"Status missing part": {
 			args: "status -d 1",
 		},
@@ -301,6 +583,36 @@ func TestError(t *testing.T) {
 		"stray arg": {
 			args: "-d 1 2 -p 1",
 		},
+		"stray arg before part": {
+			args: "-d 1 2",
+		},
+		"history without part": {
+			args: "history -d 1",
+		},
+		"cache clear of part 3": {
+			args: "cache clear -p 3",
+		},
+		"cache import without archive": {
+			args: "cache import",
+		},
+		"cache import of two archives": {
+			args: "cache import a.tar.gz b.tar.gz",
+		},
+		"cache import with flag first": {
+			args: "cache import -session state.tar.gz",
+		},
+		"check slow without unit": {
+			args: "check -slow 20",
+		},
+		"run and submit without part": {
+			args: "-d 1 -submit",
+		},
+		"submit day without part": {
+			args: "submit -d 1",
+		},
+		"submit answer without puzzle": {
+			args: "submit -a 1234",
+		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			cmd := commands{record: record{}}
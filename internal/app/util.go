@@ -6,7 +6,11 @@ import (
 	"flag"
 	"fmt"
 	"runtime/debug"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/gombrii/aoc/internal/commands"
 )
 
 type validator func() error
@@ -90,6 +94,88 @@ func inRange(fs *flag.FlagSet, flag string, v *int, min, max int) validator {
 	}
 }
 
+// buildOpts gathers the build flags of fs, telling which of them were given explicitly so that they
+// take precedence over the project config even when zero.
+func buildOpts(fs *flag.FlagSet, race *bool, tags, gcflags, pgo *string) commands.BuildOpts {
+	opts := commands.BuildOpts{Race: *race, Tags: *tags, GCFlags: *gcflags, PGO: *pgo}
+	for _, name := range []string{"race", "tags", "gcflags", "pgo"} {
+		if provided(fs, name) {
+			opts.Provided = append(opts.Provided, name)
+		}
+	}
+
+	return opts
+}
+
+// noArgs refuses arguments left over after the flags.
+func noArgs(fs *flag.FlagSet) validator {
+	return func() error {
+		if fs.NArg() > 0 {
+			fmt.Fprintf(fs.Output(), "unexpected argument: %s\n", fs.Arg(0))
+			fmt.Fprintf(fs.Output(), "Usage of %s:\n", fs.Name())
+			fs.PrintDefaults()
+			return ErrInput
+		}
+
+		return nil
+	}
+}
+
+// ifProvided applies v only if flag was explicitly provided.
+func ifProvided(fs *flag.FlagSet, flag string, v validator) validator {
+	return func() error {
+		if !provided(fs, flag) {
+			return nil
+		}
+
+		return v()
+	}
+}
+
+func provided(fs *flag.FlagSet, name string) bool {
+	found := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+
+	return found
+}
+
+// dayRange is a flag of a day, eg. "5", or a range of days, eg. "1-10".
+type dayRange struct {
+	from, to int
+}
+
+func (r *dayRange) String() string {
+	if r.from == r.to {
+		return fmt.Sprint(r.from)
+	}
+
+	return fmt.Sprintf("%d-%d", r.from, r.to)
+}
+
+func (r *dayRange) Set(s string) error {
+	from, to, isRange := strings.Cut(s, "-")
+	if !isRange {
+		to = from
+	}
+
+	var err error
+	if r.from, err = strconv.Atoi(from); err != nil {
+		return fmt.Errorf("invalid day %q", from)
+	}
+	if r.to, err = strconv.Atoi(to); err != nil {
+		return fmt.Errorf("invalid day %q", to)
+	}
+	if r.from < 1 || r.to > 25 || r.from > r.to {
+		return fmt.Errorf("days must be within 1-25 and in order: %s", s)
+	}
+
+	return nil
+}
+
 func flagSet(name string) (*flag.FlagSet, *bytes.Buffer) {
 	var buf bytes.Buffer
 	fs := flag.NewFlagSet(name, flag.ContinueOnError)
@@ -111,3 +197,44 @@ func parse(fs *flag.FlagSet, buf *bytes.Buffer, args []string, validators ...val
 
 	return nil
 }
+
+// outputFormat picks out the global flags of the output format, -json or -o json, which may appear
+// anywhere among args, and returns the rest of args.
+func outputFormat(args []string) (rest []string, json bool, err error) {
+	rest = make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; arg {
+		case "-json", "--json":
+			json = true
+		case "-o", "--o":
+			if i+1 == len(args) {
+				return nil, false, fmt.Errorf("%wflag needs an argument: %s", ErrInput, arg)
+			}
+			i++
+			if json, err = formatIsJSON(args[i]); err != nil {
+				return nil, false, err
+			}
+		default:
+			if format, ok := strings.CutPrefix("-"+strings.TrimLeft(arg, "-"), "-o="); ok {
+				if json, err = formatIsJSON(format); err != nil {
+					return nil, false, err
+				}
+				continue
+			}
+			rest = append(rest, arg)
+		}
+	}
+
+	return rest, json, nil
+}
+
+func formatIsJSON(format string) (bool, error) {
+	switch format {
+	case "text":
+		return false, nil
+	case "json":
+		return true, nil
+	default:
+		return false, fmt.Errorf("%wunknown output format %q, want text or json", ErrInput, format)
+	}
+}
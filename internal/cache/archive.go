@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// archived are the keys of every namespace that goes into an archive.
+var archived = []Key{ConfigKey{}, ProjectKey{}, PuzzleKey{}, DayKey{}}
+
+// namespaceDir returns where a namespace is kept, if it goes into an archive.
+func namespaceDir(namespace string) (string, bool) {
+	for _, key := range archived {
+		if key.namespace() != namespace {
+			continue
+		}
+		if key.scoped() {
+			return filepath.Join(projectLocation(), namespace), true
+		}
+		return filepath.Join(location(), namespace), true
+	}
+
+	return "", false
+}
+
+// Export writes the cache of the module in the working directory, along with the config of the
+// user, to w as a gzipped tarball. Only files keep is true for go into it, by their paths in the
+// archive, eg. puzzles/2024-day1-part1-input/res.
+func Export(w io.Writer, keep func(name string) bool) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	for _, key := range archived {
+		dir, _ := namespaceDir(key.namespace())
+		err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			if err != nil || d.IsDir() {
+				return err
+			}
+
+			rel, err := filepath.Rel(dir, p)
+			if err != nil {
+				return err
+			}
+			name := path.Join(key.namespace(), filepath.ToSlash(rel))
+			if !keep(name) {
+				return nil
+			}
+
+			return addFile(tw, p, name)
+		})
+		if err != nil {
+			return fmt.Errorf("archiving %s: %v", key.namespace(), err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
+
+func addFile(tw *tar.Writer, src, name string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, f)
+
+	return err
+}
+
+// Import extracts a gzipped tarball written by Export into the cache of the module in the working
+// directory and the config of the user, overwriting files by the same names. The names of the files
+// extracted are returned.
+func Import(r io.Reader) ([]string, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading archive: %v", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	names := make([]string, 0)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return names, nil
+		}
+		if err != nil {
+			return names, fmt.Errorf("reading archive: %v", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		dst, err := extractPath(hdr.Name)
+		if err != nil {
+			return names, err
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return names, fmt.Errorf("creating cache dir: %v", err)
+		}
+
+		f, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+		if err != nil {
+			return names, err
+		}
+		_, err = io.Copy(f, tr)
+		if cErr := f.Close(); err == nil {
+			err = cErr
+		}
+		if err != nil {
+			return names, fmt.Errorf("extracting %s: %v", hdr.Name, err)
+		}
+		names = append(names, hdr.Name)
+	}
+}
+
+// extractPath returns where a file of an archive goes, refusing any file outside of the namespaces
+// of an archive.
+func extractPath(name string) (string, error) {
+	clean := path.Clean(name)
+	namespace, rel, ok := strings.Cut(clean, "/")
+	dir, known := namespaceDir(namespace)
+	if !ok || !known || !fs.ValidPath(clean) {
+		return "", fmt.Errorf("unexpected file in archive: %s", name)
+	}
+
+	return filepath.Join(dir, filepath.FromSlash(rel)), nil
+}
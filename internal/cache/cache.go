@@ -25,14 +25,28 @@ func ParsePuzzleKey(keyID string) (PuzzleKey, error) {
 	return PuzzleKey{year, day, part, input}, nil
 }
 
+func ParseDayKey(keyID string) (DayKey, error) {
+	var year, day int
+	var input string
+	n, err := fmt.Sscanf(keyID, "%d-day%d-%s", &year, &day, &input)
+	if err != nil {
+		return DayKey{}, fmt.Errorf("parsing cache key ID: %v", err)
+	}
+	if n != 3 {
+		return DayKey{}, errors.New("failed parsing all parts of the cache key ID")
+	}
+
+	input += ".txt"
+
+	return DayKey{year, day, input}, nil
+}
+
 func MakePath(key Key, file string) string {
-	cache := location()
-	return filepath.Join(cache, key.namespace(), key.ID(), file)
+	return filepath.Join(keyDir(key), file)
 }
 
 func ContainsKey(key Key) (string, bool) {
-	cache := location()
-	path := filepath.Join(cache, key.namespace(), key.ID())
+	path := keyDir(key)
 
 	if _, err := os.Stat(path); err != nil {
 		return "", false
@@ -42,8 +56,7 @@ func ContainsKey(key Key) (string, bool) {
 }
 
 func Contains(key Key, file string) (string, bool) {
-	cache := location()
-	path := filepath.Join(cache, key.namespace(), key.ID(), file)
+	path := filepath.Join(keyDir(key), file)
 
 	if _, err := os.Stat(path); err != nil {
 		return "", false
@@ -53,8 +66,7 @@ func Contains(key Key, file string) (string, bool) {
 }
 
 func Store(key Key, fileName string, src string) (string, error) {
-	cPath := location()
-	dPath := filepath.Join(cPath, key.namespace(), key.ID())
+	dPath := keyDir(key)
 	dst := filepath.Join(dPath, fileName)
 
 	if _, err := os.Stat(src); err != nil {
@@ -74,8 +86,18 @@ func Clear() error {
 }
 
 func AllPuzzles() iter.Seq2[int, string] {
-	cache := location()
-	entries, _ := os.ReadDir(filepath.Join(cache, "puzzles"))
+	return all(PuzzleKey{}.namespace())
+}
+
+func AllDays() iter.Seq2[int, string] {
+	return all(DayKey{}.namespace())
+}
+
+// all yields the paths of the keys of a namespace of the module in the working directory, in order
+// of year, day and part.
+func all(namespace string) iter.Seq2[int, string] {
+	cache := projectLocation()
+	entries, _ := os.ReadDir(filepath.Join(cache, namespace))
 	sort.Slice(entries, func(i, j int) bool {
 		yi, di, pi := 0, 0, 0
 		yj, dj, pj := 0, 0, 0
@@ -92,9 +114,54 @@ func AllPuzzles() iter.Seq2[int, string] {
 	})
 	return func(yield func(int, string) bool) {
 		for i, e := range entries {
-			if !yield(i, filepath.Join(cache, "puzzles", e.Name())) {
+			if !yield(i, filepath.Join(cache, namespace, e.Name())) {
 				return
 			}
 		}
 	}
 }
+
+func Remove(key Key, fileName string) error {
+	err := os.Remove(filepath.Join(keyDir(key), fileName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// RemoveKey removes everything in the cache of key.
+func RemoveKey(key Key) error {
+	return os.RemoveAll(keyDir(key))
+}
+
+// Write writes data to a file in the cache of key, creating the cache of key if needed.
+func Write(key Key, fileName string, data []byte) (string, error) {
+	dPath := keyDir(key)
+	if err := os.MkdirAll(dPath, 0755); err != nil {
+		return "", fmt.Errorf("creating cache dir: %v", err)
+	}
+
+	dst := filepath.Join(dPath, fileName)
+
+	return dst, os.WriteFile(dst, data, 0755)
+}
+
+// Append appends data to a file in the cache of key, creating the cache of key if needed.
+func Append(key Key, fileName string, data []byte) error {
+	dPath := keyDir(key)
+	if err := os.MkdirAll(dPath, 0755); err != nil {
+		return fmt.Errorf("creating cache dir: %v", err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(dPath, fileName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -2,12 +2,24 @@ package cache
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 )
 
 type Key interface {
 	ID() string
 	namespace() string
+	// scoped tells whether the key belongs to the module in the working directory, rather than to
+	// the user.
+	scoped() bool
+}
+
+func keyDir(key Key) string {
+	if key.scoped() {
+		return filepath.Join(projectLocation(), key.namespace(), key.ID())
+	}
+
+	return filepath.Join(location(), key.namespace(), key.ID())
 }
 
 type ConfigKey struct {
@@ -21,6 +33,24 @@ func (k ConfigKey) ID() string {
 func (k ConfigKey) namespace() string {
 	return "config"
 }
+func (k ConfigKey) scoped() bool {
+	return false
+}
+
+// ProjectKey is like ConfigKey, but of the module in the working directory.
+type ProjectKey struct {
+	Domain string
+}
+
+func (k ProjectKey) ID() string {
+	return k.Domain
+}
+func (k ProjectKey) namespace() string {
+	return "project"
+}
+func (k ProjectKey) scoped() bool {
+	return true
+}
 
 type PuzzleKey struct {
 	Year  int
@@ -35,3 +65,22 @@ func (k PuzzleKey) ID() string {
 func (k PuzzleKey) namespace() string {
 	return "puzzles"
 }
+func (k PuzzleKey) scoped() bool {
+	return true
+}
+
+type DayKey struct {
+	Year  int
+	Day   int
+	Input string
+}
+
+func (k DayKey) ID() string {
+	return fmt.Sprintf("%d-day%d-%s", k.Year, k.Day, strings.TrimSuffix(k.Input, ".txt"))
+}
+func (k DayKey) namespace() string {
+	return "days"
+}
+func (k DayKey) scoped() bool {
+	return true
+}
@@ -1,11 +1,22 @@
 package cache
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"os"
+	"path"
 	"path/filepath"
+
+	"golang.org/x/mod/modfile"
 )
 
-const defaultName = "aoc-cache"
+const (
+	defaultName = "aoc-cache"
+	// LocalDir is the directory in the root of a module which, if it exists, holds the cache of
+	// the puzzles of the module instead of the cache of the OS, so that it can be committed.
+	LocalDir = ".aoc"
+)
 
 // location allows overriding cache location, for testing purposes
 func location() string {
@@ -16,3 +27,71 @@ func location() string {
 	}
 	return filepath.Join(osCache, defaultName)
 }
+
+// projectLocation is where the cache of the module in the working directory is kept. Modules are
+// told apart by both path and root, so that two clones of the same module don't share a cache.
+// Outside a module it's the cache itself.
+func projectLocation() string {
+	root, mod, ok := module()
+	if !ok {
+		return location()
+	}
+
+	local := filepath.Join(root, LocalDir)
+	if info, err := os.Stat(local); err == nil && info.IsDir() {
+		return local
+	}
+
+	sum := sha256.Sum256([]byte(mod + "\x00" + root))
+	dir := filepath.Join(location(), "projects", path.Base(mod)+"-"+hex.EncodeToString(sum[:4]))
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		adoptLegacy(dir)
+	}
+
+	return dir
+}
+
+// adoptLegacy moves the puzzles of a cache from before modules got caches of their own, along with
+// the puzzle run last, into the new cache dir of a module, so that locks survive an upgrade. The
+// first module to get a cache of its own adopts them. Anything that can't be moved is left behind.
+func adoptLegacy(dir string) {
+	base := location()
+	// The puzzle run last used to be kept in the config of the user.
+	lastRun := filepath.Join("user", "lastrun")
+	moves := map[string]string{
+		filepath.Join(base, "puzzles"):         filepath.Join(dir, "puzzles"),
+		filepath.Join(base, "days"):            filepath.Join(dir, "days"),
+		filepath.Join(base, "config", lastRun): filepath.Join(dir, "project", lastRun),
+	}
+	for src, dst := range moves {
+		if _, err := os.Stat(src); err != nil {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			continue
+		}
+		os.Rename(src, dst)
+	}
+}
+
+// module finds the root and path of the module in the working directory.
+func module() (root, mod string, ok bool) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", "", false
+	}
+
+	for {
+		data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			mod := modfile.ModulePath(data)
+			return dir, mod, mod != ""
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", false
+		}
+		dir = parent
+	}
+}
@@ -8,7 +8,10 @@ import (
 	"strings"
 )
 
-var ErrUnauthorized = errors.New("unauthorized")
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrNotFound     = errors.New("not found")
+)
 
 type Client struct {
 	*http.Client
@@ -52,7 +55,11 @@ func send(client *Client, req *http.Request, session string) (string, error) {
 		return "", fmt.Errorf("sending request: %v", err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", ErrNotFound
+	default:
 		return "", ErrUnauthorized
 	}
 
@@ -8,21 +8,42 @@ import (
 	"github.com/PuerkitoBio/goquery"
 )
 
+// Example is the first example input of a puzzle together with the example answers of the parts
+// that are revealed so far. Answers[0] is the answer of part 1.
+type Example struct {
+	Input   string
+	Answers []string
+}
+
 func GetExampleInput(client *Client, year, day int) (string, error) {
-	resp, err := client.Get(fmt.Sprintf("/%d/day/%d", year, day))
+	example, err := GetExample(client, year, day)
 	if err != nil {
 		return "", err
 	}
 
-	return firstExample(resp)
+	return example.Input, nil
 }
 
-func firstExample(html string) (string, error) {
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+func GetExample(client *Client, year, day int) (Example, error) {
+	resp, err := client.Get(fmt.Sprintf("/%d/day/%d", year, day))
+	if err != nil {
+		return Example{}, err
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(resp))
 	if err != nil {
-		return "", err
+		return Example{}, err
 	}
 
+	input, err := firstExample(doc)
+	if err != nil {
+		return Example{}, err
+	}
+
+	return Example{Input: input, Answers: exampleAnswers(doc)}, nil
+}
+
+func firstExample(doc *goquery.Document) (string, error) {
 	sel := doc.Find("body main pre code").First()
 	if sel.Length() == 0 {
 		return "", errors.New("example not found")
@@ -30,3 +51,14 @@ func firstExample(html string) (string, error) {
 
 	return strings.TrimSpace(sel.Text()), nil
 }
+
+// exampleAnswers finds the answer to the example of each revealed part. By convention it's the last
+// emphasized code in the description of the part.
+func exampleAnswers(doc *goquery.Document) []string {
+	answers := make([]string, 0, 2)
+	doc.Find("body main article.day-desc").Each(func(_ int, article *goquery.Selection) {
+		answers = append(answers, strings.TrimSpace(article.Find("code em").Last().Text()))
+	})
+
+	return answers
+}
@@ -3,6 +3,7 @@ package com_test
 import (
 	_ "embed"
 	"net/http"
+	"slices"
 	"strings"
 	"testing"
 
@@ -77,3 +78,48 @@ func TestGetExampleInput(t *testing.T) {
 		})
 	}
 }
+
+func TestGetExampleAnswers(t *testing.T) {
+	for name, params := range map[string]struct {
+		resp     string
+		expected []string
+	}{
+		"2024 d10": {
+			resp:     puzzle2024d10,
+			expected: []string{"36", "81"},
+		},
+		"2025 d1": {
+			resp:     puzzle2025d1,
+			expected: []string{"3", "6"},
+		},
+		"2025 d2": {
+			resp:     puzzle2025d2,
+			expected: []string{"1227775554", "4174379265"},
+		},
+		"2025 d3": {
+			resp:     puzzle2025d3,
+			expected: []string{"357", "3121910778619"},
+		},
+		"2025 d4": {
+			resp:     puzzle2025d4,
+			expected: []string{"13", "43"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := &com.Client{
+				Client: &http.Client{
+					Transport: RT{status: 200, body: params.resp},
+				},
+			}
+
+			res, err := com.GetExample(client, 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(res.Answers, params.expected) {
+				t.Fatalf("Got %v\nWant: %v", res.Answers, params.expected)
+			}
+		})
+	}
+}
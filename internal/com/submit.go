@@ -3,19 +3,55 @@ package com
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 )
 
 var (
-	ErrAnswerHigh    = errors.New("answer is too high")
-	ErrAnswerLow     = errors.New("answer is too low")
-	ErrAlreadySolved = errors.New("puzzle already solved")
+	ErrAnswerHigh  = errors.New("answer is too high")
+	ErrAnswerLow   = errors.New("answer is too low")
+	ErrAnswerWrong = errors.New("answer is wrong")
+	// ErrWrongLevel is returned for a part that is already solved, or a part 2 of which part 1 isn't
+	// solved yet. The server doesn't tell which.
+	ErrWrongLevel  = errors.New("not the right level")
+	ErrNotUnlocked = errors.New("puzzle not unlocked yet")
 )
 
+// WrongAnswerError is a wrong answer, which is one of ErrAnswerHigh, ErrAnswerLow or
+// ErrAnswerWrong. Wait is how long the server asks to wait before submitting again, if it says.
+type WrongAnswerError struct {
+	Err  error
+	Wait time.Duration
+}
+
+func (e WrongAnswerError) Error() string {
+	return e.Err.Error()
+}
+
+func (e WrongAnswerError) Unwrap() error {
+	return e.Err
+}
+
+// TooRecentError is returned when an answer is submitted before the wait after the previous one is
+// over. Wait is how long is left to wait.
+type TooRecentError struct {
+	Wait time.Duration
+}
+
+func (e TooRecentError) Error() string {
+	return fmt.Sprintf("answer submitted too recently, %v left to wait", e.Wait)
+}
+
 func Submit(client *Client, year, day, part int, answer string) error {
 	resp, err := client.Post(fmt.Sprintf("/%d/day/%d/answer", year, day), fmt.Sprintf("level=%d&answer=%s", part, answer))
+	if errors.Is(err, ErrNotFound) {
+		return ErrNotUnlocked
+	}
 	if err != nil {
 		return err
 	}
@@ -23,6 +59,12 @@ func Submit(client *Client, year, day, part int, answer string) error {
 	return checkResult(resp)
 }
 
+var (
+	leftToWait = regexp.MustCompile(`You have ((?:\d+[hms]\s*)+) left to wait`)
+	pleaseWait = regexp.MustCompile(`(?i)wait (\w+) minutes? before trying again`)
+	numbers    = []string{"one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten"}
+)
+
 func checkResult(html string) error {
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
 	if err != nil {
@@ -33,23 +75,54 @@ func checkResult(html string) error {
 	if sel.Length() == 0 {
 		return errors.New("result not found")
 	}
+	text := strings.Join(strings.Fields(sel.Text()), " ")
 
 	switch {
-	case strings.Contains(sel.Text(), "That's the right answer!"):
+	case strings.Contains(text, "That's the right answer!"):
 		return nil
-	case strings.Contains(sel.Text(), "That's not the right answer; your answer is too low"):
-		return ErrAnswerLow
-	case strings.Contains(sel.Text(), "That's not the right answer; your answer is too high"):
-		return ErrAnswerHigh
-	case strings.Contains(sel.Text(), "Did you already complete it?"):
-		return ErrAlreadySolved
-	//TODO: more cases
-	// - waaaaay to high
-	// - waaaaay to low
-	// - to recent, send again i xx:xx minutes
+	case strings.Contains(text, "That's not the right answer"):
+		wrong := WrongAnswerError{Err: ErrAnswerWrong, Wait: waitAfterWrong(text)}
+		switch {
+		case strings.Contains(text, "your answer is too low"):
+			wrong.Err = ErrAnswerLow
+		case strings.Contains(text, "your answer is too high"):
+			wrong.Err = ErrAnswerHigh
+		}
+		return wrong
+	case strings.Contains(text, "You gave an answer too recently"):
+		return TooRecentError{Wait: waitTooRecent(text)}
+	case strings.Contains(text, "You don't seem to be solving the right level"):
+		return ErrWrongLevel
 	default:
-		return fmt.Errorf("unrecognized result: %s", sel.Text())
+		return fmt.Errorf("unrecognized result: %s", text)
+	}
+}
+
+// waitTooRecent finds the time left to wait in text, eg. "You have 4m 52s left to wait".
+func waitTooRecent(text string) time.Duration {
+	m := leftToWait.FindStringSubmatch(text)
+	if m == nil {
+		return 0
+	}
+	wait, _ := time.ParseDuration(strings.Join(strings.Fields(m[1]), ""))
+
+	return wait
+}
+
+// waitAfterWrong finds the time to wait after a wrong answer in text, eg. "Please wait one minute
+// before trying again" or "please wait 5 minutes before trying again".
+func waitAfterWrong(text string) time.Duration {
+	m := pleaseWait.FindStringSubmatch(text)
+	if m == nil {
+		return 0
+	}
+
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		n = slices.Index(numbers, strings.ToLower(m[1])) + 1
 	}
+
+	return time.Duration(n) * time.Minute
 }
 
 //TODO: Frågan är om detta skall göra från commands. Eller det kanske inte kommer finnas nåt kommando för detta. Det kanske görs som svar Y efter ett resultat
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/gombrii/aoc/internal/com"
 )
@@ -19,6 +20,7 @@ var (
 func TestSubmit(t *testing.T) {
 	for name, params := range map[string]struct {
 		resp     string
+		status   int
 		expected error
 	}{
 		"success": {
@@ -28,12 +30,32 @@ func TestSubmit(t *testing.T) {
 			resp:     low,
 			expected: com.ErrAnswerLow,
 		},
+		"too high": {
+			resp:     answerPage("That's not the right answer; your answer is too high. Please wait one minute before trying again."),
+			expected: com.ErrAnswerHigh,
+		},
+		"wrong": {
+			resp:     answerPage("That's not the right answer. If you're stuck, make sure you're using the full input data."),
+			expected: com.ErrAnswerWrong,
+		},
+		"wrong level": {
+			resp:     answerPage("You don't seem to be solving the right level.  Did you already complete it? [Return to Day 1]"),
+			expected: com.ErrWrongLevel,
+		},
+		"not unlocked": {
+			status:   404,
+			expected: com.ErrNotUnlocked,
+		},
 		//TODO: More cases when I have collected test data
 	} {
 		t.Run(name, func(t *testing.T) {
+			status := params.status
+			if status == 0 {
+				status = 200
+			}
 			client := &com.Client{
 				Client: &http.Client{
-					Transport: RT{status: 200, body: params.resp},
+					Transport: RT{status: status, body: params.resp},
 				},
 			}
 
@@ -51,3 +73,52 @@ func TestSubmit(t *testing.T) {
 		})
 	}
 }
+
+func TestSubmitWait(t *testing.T) {
+	for name, params := range map[string]struct {
+		resp string
+		wait time.Duration
+	}{
+		"too recent": {
+			resp: answerPage("You gave an answer too recently; you have to wait after submitting an answer before trying again.  You have 4m 52s left to wait. [Return to Day 1]"),
+			wait: 4*time.Minute + 52*time.Second,
+		},
+		"wrong in words": {
+			resp: low,
+			wait: time.Minute,
+		},
+		"wrong in digits": {
+			resp: answerPage("That's not the right answer; your answer is too high. Because you have guessed incorrectly 4 times on this puzzle, please wait 5 minutes before trying again."),
+			wait: 5 * time.Minute,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := &com.Client{
+				Client: &http.Client{
+					Transport: RT{status: 200, body: params.resp},
+				},
+			}
+
+			err := com.Submit(client, 0, 0, 0, "")
+
+			var wait time.Duration
+			var wrong com.WrongAnswerError
+			var tooRecent com.TooRecentError
+			switch {
+			case errors.As(err, &wrong):
+				wait = wrong.Wait
+			case errors.As(err, &tooRecent):
+				wait = tooRecent.Wait
+			default:
+				t.Fatalf("Got %v\nWant error with a wait", err)
+			}
+			if wait != params.wait {
+				t.Fatalf("Got wait %v\nWant: %v", wait, params.wait)
+			}
+		})
+	}
+}
+
+func answerPage(answer string) string {
+	return "<html><body><main><article><p>" + answer + "</p></article></main></body></html>"
+}
@@ -0,0 +1,190 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gombrii/aoc/internal/cache"
+	"github.com/gombrii/aoc/internal/config"
+	"github.com/gombrii/aoc/internal/files"
+)
+
+// AnswersFile records the answers of locked puzzles in the root of the module, so that locks can be
+// committed along with the solutions. It's read along with the cache, so that a fresh clone of a
+// module can be checked without any cache at all.
+const AnswersFile = "aoc.lock"
+
+// answer is what is recorded about a locked puzzle in AnswersFile. Best is optional, and only kept
+// up to date if present.
+type answer struct {
+	Answer string          `json:"answer"`
+	Best   config.Duration `json:"best,omitempty"`
+}
+
+// answers are answers by answerName.
+type answers map[string]answer
+
+// answersMu guards AnswersFile against puzzles being run at the same time.
+var answersMu sync.Mutex
+
+// answerName names a puzzle in AnswersFile, eg. 2024/day1/part2/input.txt.
+func answerName(key cache.PuzzleKey) string {
+	return fmt.Sprintf("%d/day%d/part%d/%s", key.Year, key.Day, key.Part, key.Input)
+}
+
+func parseAnswerName(name string) (cache.PuzzleKey, error) {
+	var key cache.PuzzleKey
+	n, err := fmt.Sscanf(name, "%d/day%d/part%d/%s", &key.Year, &key.Day, &key.Part, &key.Input)
+	if err != nil || n != 4 {
+		return cache.PuzzleKey{}, fmt.Errorf("invalid puzzle %q in %s", name, AnswersFile)
+	}
+
+	return key, nil
+}
+
+// readAnswers reads AnswersFile. A missing file has no answers.
+func readAnswers() (answers, error) {
+	answersMu.Lock()
+	defer answersMu.Unlock()
+
+	return loadAnswers()
+}
+
+func loadAnswers() (answers, error) {
+	data, err := os.ReadFile(AnswersFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return answers{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %v", AnswersFile, err)
+	}
+
+	ans := answers{}
+	if err := json.Unmarshal(data, &ans); err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", AnswersFile, err)
+	}
+
+	return ans, nil
+}
+
+// editAnswers applies edit to the answers of AnswersFile, and writes them back if edit tells they
+// changed. The file is removed when no answers are left.
+func editAnswers(edit func(answers) bool) error {
+	answersMu.Lock()
+	defer answersMu.Unlock()
+
+	ans, err := loadAnswers()
+	if err != nil {
+		return err
+	}
+	if !edit(ans) {
+		return nil
+	}
+
+	if len(ans) == 0 {
+		if err := os.Remove(AnswersFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("removing %s: %v", AnswersFile, err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(ans, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding %s: %v", AnswersFile, err)
+	}
+
+	return os.WriteFile(AnswersFile, append(data, '\n'), 0644)
+}
+
+// lockAnswer records res as the answer of the puzzle of key, along with its best duration, if known.
+func lockAnswer(key cache.PuzzleKey, res string, best time.Duration) error {
+	return editAnswers(func(ans answers) bool {
+		a := answer{Answer: res}
+		if best != math.MaxInt64 {
+			a.Best = config.Duration(best)
+		}
+		ans[answerName(key)] = a
+		return true
+	})
+}
+
+func unlockAnswer(key cache.PuzzleKey) error {
+	return editAnswers(func(ans answers) bool {
+		if _, ok := ans[answerName(key)]; !ok {
+			return false
+		}
+		delete(ans, answerName(key))
+		return true
+	})
+}
+
+// improveBest records a new best duration of the puzzle of key, unless its best isn't recorded.
+func improveBest(key cache.PuzzleKey, dur time.Duration) error {
+	return editAnswers(func(ans answers) bool {
+		a, ok := ans[answerName(key)]
+		if !ok || a.Best == 0 || dur >= time.Duration(a.Best) {
+			return false
+		}
+		a.Best = config.Duration(dur)
+		ans[answerName(key)] = a
+		return true
+	})
+}
+
+// lockedPuzzles lists the puzzles locked either in cache or in AnswersFile, in order.
+func lockedPuzzles() ([]cache.PuzzleKey, error) {
+	ans, err := readAnswers()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]cache.PuzzleKey, 0, len(ans))
+	for name := range ans {
+		key, err := parseAnswerName(name)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	for _, l := range cache.AllPuzzles() {
+		key, err := cache.ParsePuzzleKey(filepath.Base(l))
+		if err != nil {
+			return nil, fmt.Errorf("parsing cache key: %v", err)
+		}
+		if _, ok := ans[answerName(key)]; ok {
+			continue
+		}
+		data, err := files.Read(filepath.Join(l, files.Lock))
+		if err != nil {
+			return nil, fmt.Errorf("reading cache: %v", err)
+		}
+		if locked, _ := strconv.ParseBool(strings.TrimSpace(string(data))); locked {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.SortFunc(keys, func(a, b cache.PuzzleKey) int {
+		switch {
+		case a.Year != b.Year:
+			return a.Year - b.Year
+		case a.Day != b.Day:
+			return a.Day - b.Day
+		case a.Part != b.Part:
+			return a.Part - b.Part
+		default:
+			return strings.Compare(a.Input, b.Input)
+		}
+	})
+
+	return keys, nil
+}
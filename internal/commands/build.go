@@ -0,0 +1,216 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gombrii/aoc/internal/cache"
+	"github.com/gombrii/aoc/internal/config"
+	"github.com/gombrii/aoc/internal/exec"
+	"github.com/gombrii/aoc/internal/files"
+)
+
+// BuildOpts holds the options of the go toolchain when building puzzles. They mirror the flags of
+// go build by the same names.
+type BuildOpts struct {
+	Race    bool
+	Tags    string
+	GCFlags string
+	PGO     string
+	// Provided names the options given explicitly, eg. "race", which then hold even if zero, such
+	// as -race=false or -tags="" overriding the project config.
+	Provided []string
+}
+
+// orConfig fills in the options neither set in opts nor provided explicitly with the defaults of
+// the project config.
+func (opts BuildOpts) orConfig(cfg config.Build) BuildOpts {
+	given := func(name string, set bool) bool {
+		return set || slices.Contains(opts.Provided, name)
+	}
+
+	if !given("race", opts.Race) {
+		opts.Race = cfg.Race
+	}
+	if !given("tags", opts.Tags != "") {
+		opts.Tags = cfg.Tags
+	}
+	if !given("gcflags", opts.GCFlags != "") {
+		opts.GCFlags = cfg.GCFlags
+	}
+	if !given("pgo", opts.PGO != "") {
+		opts.PGO = cfg.PGO
+	}
+
+	return opts
+}
+
+// flags translates opts into flags of go build.
+func (opts BuildOpts) flags() []string {
+	flags := make([]string, 0)
+	if opts.Race {
+		flags = append(flags, "-race")
+	}
+	if opts.Tags != "" {
+		flags = append(flags, "-tags="+opts.Tags)
+	}
+	if opts.GCFlags != "" {
+		flags = append(flags, "-gcflags="+opts.GCFlags)
+	}
+	if opts.PGO != "" {
+		flags = append(flags, "-pgo="+opts.PGO)
+	}
+
+	return flags
+}
+
+// errBuild is returned when a runner fails to compile, typically due to an error in the solution.
+type errBuild struct {
+	output []byte
+}
+
+func (e errBuild) Error() string {
+	return fmt.Sprintf("compiling runner:\n%s", e.output)
+}
+
+// buildRunner compiles the runner src stored under key with the given flags of go build and
+// returns the path of the binary. The binary is only rebuilt when the source of the runner, of the
+// solution package pkg or of any package of the module it depends on has changed since the last
+// build. Binaries built with different flags are kept apart, so that switching between them
+// doesn't force a rebuild.
+func buildRunner(ctx context.Context, key cache.Key, src, pkg string, flags []string) (string, error) {
+	hash, err := sourceHash(src, pkg, flags)
+	if err != nil {
+		return "", fmt.Errorf("hashing source: %v", err)
+	}
+
+	bin := cache.MakePath(key, variant(files.Binary, flags))
+	hName := variant(files.Hash, flags)
+	if hPath, ok := cache.Contains(key, hName); ok && files.Exists(bin) {
+		if data, err := files.Read(hPath); err == nil && string(data) == hash {
+			return bin, nil
+		}
+	}
+
+	if out, err := exec.Build(ctx, src, bin, flags...); err != nil {
+		return "", errBuild{output: out}
+	}
+
+	if err := files.Write(cache.MakePath(key, hName), []byte(hash)); err != nil {
+		return "", fmt.Errorf("writing hash: %v", err)
+	}
+
+	return bin, nil
+}
+
+// variant names the file of a build with flags, eg. runner-1a2b3c4d for runner. A build without
+// flags keeps the plain name.
+func variant(name string, flags []string) string {
+	if len(flags) == 0 {
+		return name
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(flags, "\x00")))
+	ext := filepath.Ext(name)
+
+	return fmt.Sprintf("%s-%s%s", strings.TrimSuffix(name, ext), hex.EncodeToString(sum[:4]), ext)
+}
+
+// pgoProfile returns the profile file given to -pgo among flags, if any.
+func pgoProfile(flags []string) string {
+	for _, f := range flags {
+		if p, ok := strings.CutPrefix(f, "-pgo="); ok && p != "auto" && p != "off" {
+			return p
+		}
+	}
+
+	return ""
+}
+
+// sourceHash hashes the runner src together with go.mod, go.sum and every file that goes into the
+// build of the packages in the main module that pkg depends on, pkg included, such as Go, cgo,
+// assembly and embedded files, as selected by the flags of go build. Packages outside the main
+// module are pinned by go.sum. A PGO profile, if any, is hashed too, as is the toolchain the runner
+// is built with.
+func sourceHash(src, pkg string, flags []string) (string, error) {
+	toolchain, err := exec.CommandAndCapture("go", "env", "GOVERSION", "GOOS", "GOARCH", "CGO_ENABLED")
+	if err != nil {
+		return "", fmt.Errorf("reading go env: %v", err)
+	}
+
+	deps, err := sourceFiles(pkg, flags)
+	if err != nil {
+		return "", fmt.Errorf("listing dependencies: %v", err)
+	}
+	sort.Strings(deps)
+
+	h := sha256.New()
+	h.Write(toolchain)
+	for _, path := range append([]string{src, "go.mod", "go.sum", pgoProfile(flags)}, deps...) {
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s %d\n", path, len(data))
+		h.Write(data)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// listedPackage is the part of a package listed by go list -json that tells which files go into
+// its build.
+type listedPackage struct {
+	Dir    string
+	Module *struct{ Main bool }
+
+	GoFiles, CgoFiles, CFiles, CXXFiles, MFiles, HFiles, FFiles, SFiles []string
+	SwigFiles, SwigCXXFiles, SysoFiles, EmbedFiles                      []string
+}
+
+// sourceFiles lists the paths of the files that go into the build of the packages in the main
+// module that pkg depends on, pkg included, when built with flags. Build tags among them may add
+// files and dependencies.
+func sourceFiles(pkg string, flags []string) ([]string, error) {
+	args := append([]string{"list", "-e", "-deps",
+		"-json=Dir,Module,GoFiles,CgoFiles,CFiles,CXXFiles,MFiles,HFiles,FFiles,SFiles,SwigFiles,SwigCXXFiles,SysoFiles,EmbedFiles"},
+		flags...)
+	out, err := exec.CommandAndCapture("go", append(args, pkg)...)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0)
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for dec.More() {
+		var p listedPackage
+		if err := dec.Decode(&p); err != nil {
+			return nil, fmt.Errorf("parsing go list: %v", err)
+		}
+		if p.Module == nil || !p.Module.Main {
+			continue
+		}
+		for _, names := range [][]string{
+			p.GoFiles, p.CgoFiles, p.CFiles, p.CXXFiles, p.MFiles, p.HFiles, p.FFiles, p.SFiles,
+			p.SwigFiles, p.SwigCXXFiles, p.SysoFiles, p.EmbedFiles,
+		} {
+			for _, name := range names {
+				paths = append(paths, filepath.Join(p.Dir, name))
+			}
+		}
+	}
+
+	return paths, nil
+}
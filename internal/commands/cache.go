@@ -1,7 +1,293 @@
 package commands
 
-import "github.com/gombrii/aoc/internal/cache"
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
-func (c Commands) ClearCache() error {
-	return cache.Clear()
+	"github.com/gombrii/aoc/internal/cache"
+	"github.com/gombrii/aoc/internal/files"
+)
+
+// ClearOpts narrows down what ClearCache removes. Without any, everything aoc keeps is removed,
+// the login included.
+type ClearOpts struct {
+	// Year, Day and Part narrow down the puzzles to clear. Zero matches any.
+	Year int
+	Day  int
+	Part int
+	// Runners only removes the runners of puzzles, keeping their results and locks.
+	Runners bool
+}
+
+func (opts ClearOpts) selects(e cacheEntry) bool {
+	switch {
+	case opts.Year != 0 && e.year != opts.Year,
+		opts.Day != 0 && e.day != opts.Day,
+		opts.Part != 0 && e.part != opts.Part:
+		return false
+	default:
+		return true
+	}
+}
+
+func (c Commands) ClearCache(opts ClearOpts) error {
+	if opts == (ClearOpts{}) {
+		return cache.Clear()
+	}
+
+	entries, err := cacheEntries()
+	if err != nil {
+		return err
+	}
+
+	cleared := 0
+	for _, e := range entries {
+		if !opts.selects(e) {
+			continue
+		}
+		if opts.Runners {
+			err = removeRunners(e)
+		} else {
+			err = cache.RemoveKey(e.key)
+		}
+		if err != nil {
+			return fmt.Errorf("clearing %s: %v", e.name(), err)
+		}
+		cleared++
+	}
+
+	if opts.Runners {
+		fmt.Printf("Cleared runners of %d records\n", cleared)
+	} else {
+		fmt.Printf("Cleared %d records\n", cleared)
+	}
+
+	return nil
+}
+
+// PruneCache removes the records of the puzzles of days without solutions in the module.
+func (c Commands) PruneCache() error {
+	// Outside of the module every solution would seem to be gone.
+	if _, err := currentModulePath(); err != nil {
+		return fmt.Errorf("getting module name: %v", err)
+	}
+
+	entries, err := cacheEntries()
+	if err != nil {
+		return err
+	}
+
+	pruned := 0
+	for _, e := range entries {
+		if files.Exists(filepath.Join(fmt.Sprint(e.year), "solutions", fmt.Sprintf("day%d", e.day))) {
+			continue
+		}
+		if err := cache.RemoveKey(e.key); err != nil {
+			return fmt.Errorf("pruning %s: %v", e.name(), err)
+		}
+		fmt.Printf("Pruned %s with %s\n", e.name(), e.input)
+		pruned++
+	}
+
+	fmt.Printf("Pruned %d records\n", pruned)
+
+	return nil
+}
+
+// ListCache lists the records in the cache of the module, with their size, whether they're locked
+// and when they were last run.
+func (c Commands) ListCache() error {
+	entries, err := cacheEntries()
+	if err != nil {
+		return err
+	}
+
+	docs := make([]cacheDoc, 0, len(entries))
+	for _, e := range entries {
+		doc, err := e.doc()
+		if err != nil {
+			return fmt.Errorf("reading %s: %v", e.name(), err)
+		}
+		docs = append(docs, doc)
+	}
+
+	if c.json {
+		return printJSON(docs)
+	}
+
+	fmt.Printf("%-16s %-12s %10s  %s  %s\n", "PUZZLE", "INPUT", "SIZE", "LOCKED", "LAST RUN")
+	for i, doc := range docs {
+		// Padded by hand, as the marks take up more bytes than columns.
+		locked := "□     "
+		switch {
+		case doc.Locked:
+			locked = "▣     "
+		case doc.Part == 0:
+			locked = "      " // both parts of a day are never locked as one
+		}
+		lastRun := "-"
+		if !doc.LastRun.IsZero() {
+			lastRun = doc.LastRun.Format(time.DateTime)
+		}
+		fmt.Printf("%-16s %-12s %10s  %s  %s\n", entries[i].name(), doc.Input, byteSize(uint64(doc.Size)), locked, lastRun)
+	}
+
+	return nil
+}
+
+// cacheEntry is the cache of one part of a puzzle, or of both parts of a day.
+type cacheEntry struct {
+	key   cache.Key
+	path  string
+	year  int
+	day   int
+	part  int // zero for both parts
+	input string
+}
+
+func (e cacheEntry) name() string {
+	if e.part == 0 {
+		return fmt.Sprintf("%d/day%d", e.year, e.day)
+	}
+
+	return fmt.Sprintf("%d/day%d/part%d", e.year, e.day, e.part)
+}
+
+// doc sums up the files of an entry. The last run is when any of them last changed.
+func (e cacheEntry) doc() (cacheDoc, error) {
+	doc := cacheDoc{Year: e.year, Day: e.day, Part: e.part, Input: e.input}
+
+	dirEntries, err := os.ReadDir(e.path)
+	if err != nil {
+		return cacheDoc{}, err
+	}
+	for _, d := range dirEntries {
+		info, err := d.Info()
+		if err != nil {
+			return cacheDoc{}, err
+		}
+		doc.Size += info.Size()
+		if info.ModTime().After(doc.LastRun) {
+			doc.LastRun = info.ModTime()
+		}
+	}
+
+	if key, ok := e.key.(cache.PuzzleKey); ok {
+		rec, err := readRecord(key)
+		if err != nil {
+			return cacheDoc{}, err
+		}
+		doc.Locked = rec.locked
+	}
+
+	return doc, nil
+}
+
+// cacheEntries lists the cache of the module, the parts of a day before the day.
+func cacheEntries() ([]cacheEntry, error) {
+	entries := make([]cacheEntry, 0)
+	for _, l := range cache.AllPuzzles() {
+		key, err := cache.ParsePuzzleKey(filepath.Base(l))
+		if err != nil {
+			return nil, fmt.Errorf("parsing cache key: %v", err)
+		}
+		entries = append(entries, cacheEntry{key: key, path: l, year: key.Year, day: key.Day, part: key.Part, input: key.Input})
+	}
+	for _, l := range cache.AllDays() {
+		key, err := cache.ParseDayKey(filepath.Base(l))
+		if err != nil {
+			return nil, fmt.Errorf("parsing cache key: %v", err)
+		}
+		entries = append(entries, cacheEntry{key: key, path: l, year: key.Year, day: key.Day, input: key.Input})
+	}
+
+	slices.SortStableFunc(entries, func(a, b cacheEntry) int {
+		return cmp.Or(cmp.Compare(a.year, b.year), cmp.Compare(a.day, b.day))
+	})
+
+	return entries, nil
+}
+
+// removeRunners removes the runners of an entry, along with what's left behind by building and
+// running them.
+func removeRunners(e cacheEntry) error {
+	dirEntries, err := os.ReadDir(e.path)
+	if err != nil {
+		return err
+	}
+	for _, d := range dirEntries {
+		if !isRunnerFile(d.Name()) {
+			continue
+		}
+		if err := cache.Remove(e.key, d.Name()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExportCache writes the records of the puzzles of the module, such as results, durations and
+// locks, along with the config of the user, to stdout as a gzipped tarball. The session token is
+// only included if asked for.
+func (c Commands) ExportCache(session bool) error {
+	if isTerminal(os.Stdout) {
+		return errors.New("refusing to write an archive to a terminal, redirect it to a file")
+	}
+
+	return cache.Export(os.Stdout, func(name string) bool {
+		if path.Base(name) == files.Session {
+			return session
+		}
+		return !isLocalFile(name)
+	})
+}
+
+// ImportCache extracts an archive written by ExportCache, or stdin for "-", into the cache.
+func (c Commands) ImportCache(src string) error {
+	in := os.Stdin
+	if src != stdinInput {
+		f, err := os.Open(src)
+		if err != nil {
+			return fmt.Errorf("opening archive: %v", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	names, err := cache.Import(in)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d files\n", len(names))
+
+	return nil
+}
+
+// isLocalFile tells whether a file of the cache only makes sense on the computer it was made on.
+// Runners embed absolute paths and are regenerated by the next run anyway.
+func isLocalFile(name string) bool {
+	switch base := path.Base(name); {
+	case isRunnerFile(base), base == files.Input:
+		return true
+	case base == files.CPUProfile, base == files.MemProfile, base == files.Trace:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRunnerFile tells whether a file of the cache is a runner, its source or build hash, or report.
+func isRunnerFile(name string) bool {
+	runner := strings.TrimSuffix(files.Binary, filepath.Ext(files.Binary))
+
+	return strings.HasPrefix(name, runner) || strings.HasPrefix(name, files.Hash) || name == files.Report
 }
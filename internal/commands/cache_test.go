@@ -3,6 +3,7 @@ package commands_test
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gombrii/aoc/internal/commands"
@@ -14,7 +15,7 @@ func TestClearCache(t *testing.T) {
 		t.Errorf("creating dir in cache: %v", err)
 	}
 
-	if err := (commands.Commands{}).ClearCache(); err != nil {
+	if err := (commands.Commands{}).ClearCache(commands.ClearOpts{}); err != nil {
 		t.Errorf("calling ClearCache: %v", err)
 	}
 
@@ -27,11 +28,11 @@ func TestClearNonexistentCache(t *testing.T) {
 	_, testCache, _ := prepare(t)
 	cmd := commands.Commands{}
 
-	if err := cmd.ClearCache(); err != nil {
+	if err := cmd.ClearCache(commands.ClearOpts{}); err != nil {
 		t.Errorf("calling ClearCache: %v", err)
 	}
 
-	if err := cmd.ClearCache(); err != nil {
+	if err := cmd.ClearCache(commands.ClearOpts{}); err != nil {
 		t.Errorf("calling ClearCache: %v", err)
 	}
 
@@ -39,3 +40,149 @@ func TestClearNonexistentCache(t *testing.T) {
 		t.Error("cache wasn't cleared")
 	}
 }
+
+func TestExportImportCache(t *testing.T) {
+	testRoot, testCache, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	cmd := commands.Commands{}
+	if err := cmd.Run(2024, 1, 1, "input.txt", commands.RunOpts{}); err != nil {
+		t.Fatalf("calling Run: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(testCache, "config", "user"), 0755); err != nil {
+		t.Fatalf("creating config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(testCache, "config", "user", "session"), []byte("secret"), 0644); err != nil {
+		t.Fatalf("logging in: %v", err)
+	}
+
+	var err error
+	archive := captureStdout(t, func() { err = cmd.ExportCache(false) })
+	if err != nil {
+		t.Fatalf("calling ExportCache: %v", err)
+	}
+	src := filepath.Join(t.TempDir(), "state.tar.gz")
+	if err := os.WriteFile(src, archive, 0644); err != nil {
+		t.Fatalf("writing archive: %v", err)
+	}
+
+	if err := cmd.ClearCache(commands.ClearOpts{}); err != nil {
+		t.Fatalf("calling ClearCache: %v", err)
+	}
+	captureStdout(t, func() { err = cmd.ImportCache(src) })
+	if err != nil {
+		t.Fatalf("calling ImportCache: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(puzzleDir(t, "2024-day1-part1-input"), "res")); err != nil {
+		t.Error("result wasn't imported")
+	}
+	if _, err := os.Stat(filepath.Join(puzzleDir(t, "2024-day1-part1-input"), "runner.go")); err == nil {
+		t.Error("runner was imported")
+	}
+	if _, err := os.Stat(filepath.Join(testCache, "config", "user", "session")); err == nil {
+		t.Error("session was imported without being asked for")
+	}
+
+	if err := cmd.Run(2024, 1, 1, "input.txt", commands.RunOpts{}); err != nil {
+		t.Errorf("calling Run after import: %v", err)
+	}
+}
+
+func TestManageCache(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	cmd := commands.Commands{}
+	for _, part := range []int{0, 1, 2} {
+		if err := cmd.Run(2024, 1, part, "input.txt", commands.RunOpts{}); err != nil {
+			t.Fatalf("calling Run: %v", err)
+		}
+	}
+	if err := cmd.Lock(2024, 1, 1, "input.txt"); err != nil {
+		t.Fatalf("calling Lock: %v", err)
+	}
+	part1, part2 := puzzleDir(t, "2024-day1-part1-input"), puzzleDir(t, "2024-day1-part2-input")
+
+	var err error
+	out := captureStdout(t, func() { err = cmd.ListCache() })
+	if err != nil {
+		t.Errorf("calling ListCache: %v", err)
+	}
+	for _, name := range []string{"2024/day1 ", "2024/day1/part1", "2024/day1/part2", "▣"} {
+		if !strings.Contains(string(out), name) {
+			t.Errorf("ListCache didn't list %q:\n%s", name, out)
+		}
+	}
+
+	captureStdout(t, func() { err = cmd.ClearCache(commands.ClearOpts{Runners: true}) })
+	if err != nil {
+		t.Errorf("calling ClearCache of runners: %v", err)
+	}
+	if runners, _ := filepath.Glob(filepath.Join(part1, "runner*")); len(runners) > 0 {
+		t.Errorf("runners weren't cleared: %v", runners)
+	}
+	if _, err := os.Stat(filepath.Join(part1, "res")); err != nil {
+		t.Error("result was cleared with runners")
+	}
+
+	captureStdout(t, func() { err = cmd.ClearCache(commands.ClearOpts{Part: 2}) })
+	if err != nil {
+		t.Errorf("calling ClearCache of part: %v", err)
+	}
+	if _, err := os.Stat(part2); err == nil {
+		t.Error("part 2 wasn't cleared")
+	}
+	if _, err := os.Stat(part1); err != nil {
+		t.Error("part 1 was cleared along with part 2")
+	}
+
+	if err := os.RemoveAll(filepath.Join(testRoot, "2024", "solutions", "day1")); err != nil {
+		t.Fatalf("removing solution: %v", err)
+	}
+	captureStdout(t, func() { err = cmd.PruneCache() })
+	if err != nil {
+		t.Errorf("calling PruneCache: %v", err)
+	}
+	if _, err := os.Stat(part1); err == nil {
+		t.Error("puzzle without solution wasn't pruned")
+	}
+}
+
+func TestAdoptLegacyCache(t *testing.T) {
+	testRoot, testCache, wd := prepare(t)
+
+	// A cache from before modules got caches of their own, with a locked puzzle run last.
+	initCache(t, wd, testCache)
+	legacy := filepath.Join(testCache, "puzzles", "2024-day1-part1-input")
+	for name, data := range map[string]string{
+		filepath.Join(legacy, "lock"):                          "true",
+		filepath.Join(legacy, "res"):                           "NOT IMPLEMENTED!",
+		filepath.Join(testCache, "config", "user", "lastrun"): "2024-day1-part1-input",
+	} {
+		writeFile(t, name, data)
+	}
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	var err error
+	out := captureStdout(t, func() { err = (commands.Commands{}).Check(commands.CheckOpts{}) })
+	if err != nil {
+		t.Fatalf("calling Check: %v", err)
+	}
+	if !strings.Contains(string(out), "1 passed") {
+		t.Errorf("Check didn't check the locked puzzle of the legacy cache:\n%s", out)
+	}
+
+	if _, err := os.Stat(legacy); err == nil {
+		t.Error("legacy cache was left behind")
+	}
+	lastRun, _ := filepath.Glob(filepath.Join(testCache, "projects", "*", "project", "user", "lastrun"))
+	if len(lastRun) != 1 {
+		t.Error("last run puzzle wasn't adopted")
+	}
+}
@@ -1,52 +1,176 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"path/filepath"
-	"strconv"
+	"math"
+	"os"
+	"os/signal"
+	"path"
+	"runtime"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/gombrii/aoc/internal/cache"
-	"github.com/gombrii/aoc/internal/exec"
+	"github.com/gombrii/aoc/internal/config"
 	"github.com/gombrii/aoc/internal/files"
 )
 
 type outcome struct {
 	i       int
+	run     puzzleRun
 	success bool
+	slow    bool // correct, but slower than allowed
 	err     error
 }
 
 type printable struct {
+	key    cache.PuzzleKey
 	name   string
 	result string
+	colour string
+	timing string // duration compared to the best one
+	doc    puzzleDoc
 }
 
-func (c Commands) Check() error {
+// CheckOpts holds the optional ways of checking puzzles.
+type CheckOpts struct {
+	// Timeout is the time each puzzle may run before it's killed. Defaults to the timeout of the
+	// project config, if any.
+	Timeout time.Duration
+	// Build holds options of the go toolchain. Each defaults to the project config, if any.
+	Build BuildOpts
+	// Year, FromDay, ToDay and Part narrow down the puzzles to check. Zero matches any.
+	Year    int
+	FromDay int
+	ToDay   int
+	Part    int
+	// Match narrows down the puzzles to check to those whose name, eg. 2024/day1/part2, matches
+	// any of these glob patterns.
+	Match []string
+	// Failed only checks the puzzles that didn't pass the last time they were checked.
+	Failed bool
+	// Jobs is the number of puzzles built, and run, at the same time. Defaults to GOMAXPROCS.
+	Jobs int
+	// SerialTiming runs one puzzle at a time, so that their durations can fairly be compared to
+	// their best ones.
+	SerialTiming bool
+	// Slow fails puzzles that got slower than their best by more than this. Defaults to the
+	// threshold of the project config, if any.
+	Slow config.Threshold
+}
+
+// selects tells whether the puzzle of key is among the ones to check.
+func (opts CheckOpts) selects(key cache.PuzzleKey) bool {
+	switch {
+	case opts.Year != 0 && key.Year != opts.Year,
+		opts.FromDay != 0 && key.Day < opts.FromDay,
+		opts.ToDay != 0 && key.Day > opts.ToDay,
+		opts.Part != 0 && key.Part != opts.Part:
+		return false
+	}
+
+	if len(opts.Match) > 0 {
+		name := fmt.Sprintf("%d/day%d/part%d", key.Year, key.Day, key.Part)
+		matched := false
+		for _, pattern := range opts.Match {
+			if ok, _ := path.Match(pattern, name); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if opts.Failed {
+		last, ok := lastCheck(key)
+		return ok && last != outcomeCorrect
+	}
+
+	return true
+}
+
+// lastCheck returns the outcome of the last check of the puzzle of key, if it has been checked.
+func lastCheck(key cache.PuzzleKey) (string, bool) {
+	cPath, ok := cache.Contains(key, files.LastCheck)
+	if !ok {
+		return "", false
+	}
+
+	data, err := files.Read(cPath)
+	if err != nil {
+		return "", false
+	}
+
+	return strings.TrimSpace(string(data)), true
+}
+
+func (c Commands) Check(opts CheckOpts) error {
+	for _, pattern := range opts.Match {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	mod, err := currentModulePath()
+	if err != nil {
+		return fmt.Errorf("getting module name: %v", err)
+	}
+
+	jobs := opts.Jobs
+	if jobs <= 0 {
+		jobs = runtime.GOMAXPROCS(0)
+	}
+	runJobs := jobs
+	if opts.SerialTiming {
+		runJobs = 1
+	}
+
+	env := runEnv{
+		mod:     mod,
+		build:   opts.Build.orConfig(cfg.Build).flags(),
+		timeout: opts.Timeout,
+		builds:  make(chan struct{}, jobs),
+		runs:    make(chan struct{}, runJobs),
+		vcs:     sync.OnceValue(currentVCS),
+	}
+	if env.timeout == 0 {
+		env.timeout = time.Duration(cfg.Timeout)
+	}
+	if opts.Slow.IsZero() {
+		opts.Slow = cfg.Slow
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
 	ch := make(chan outcome)
 	wg := sync.WaitGroup{}
 	puzzles := make([]printable, 0)
 	i := 0
 
-	for _, l := range cache.AllPuzzles() {
-		data, err := files.ReadAll(map[string]string{
-			files.Lock: filepath.Join(l, files.Lock),
-		})
-		if err != nil {
-			return fmt.Errorf("reading cache: %v", err)
-		}
-
-		locked, _ := strconv.ParseBool(data[files.Lock])
-		if locked {
-			wg.Add(1)
-			go runnerRoutine(filepath.Join(l, files.Runner), i, ch, &wg)
-			printParts := strings.Split(filepath.Base(l), "-")
-			printName := strings.Join(printParts[:len(printParts)-1], "/")
-			puzzles = append(puzzles, printable{name: printName})
-			i++
+	keys, err := lockedPuzzles()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if !opts.selects(key) {
+			continue
 		}
+		wg.Add(1)
+		go runnerRoutine(ctx, env, key, i, ch, &wg)
+		printName := fmt.Sprintf("%d/day%d/part%d", key.Year, key.Day, key.Part)
+		puzzles = append(puzzles, printable{key: key, name: printName})
+		i++
 	}
 
 	go func() {
@@ -54,10 +178,15 @@ func (c Commands) Check() error {
 		close(ch)
 	}()
 
+	// Redrawing the table in place only works on a terminal. Elsewhere, such as in CI logs, each
+	// line is printed once its outcome is known.
+	live := !c.json && isTerminal(os.Stdout)
+
 	t := time.NewTicker(50 * time.Millisecond)
 	defer t.Stop()
 	spinner := `-\|/`
 
+	sum := summary{slowGate: !opts.Slow.IsZero()}
 	i = 0
 	for {
 		select {
@@ -65,41 +194,158 @@ func (c Commands) Check() error {
 			i++
 		case out, ok := <-ch:
 			if !ok {
-				print(i, puzzles, spinner)
-				return nil
+				switch {
+				case c.json:
+					printJSON(checkDocs(puzzles))
+				case live:
+					print(i, puzzles, spinner)
+				}
+				if ctx.Err() != nil {
+					return errInterrupted
+				}
+				if !c.json {
+					fmt.Println(sum)
+				}
+				return sum.err()
 			}
-			if out.err != nil {
-				puzzles[out.i].result = "\033[38;2;255;0;0merror\033[0m"
+			if best := out.run.before.dur; out.success && best != math.MaxInt64 {
+				out.slow = opts.Slow.Exceeded(best, out.run.res.Dur)
+			}
+			puzzles[out.i].doc = checkDoc(puzzles[out.i].key, out)
+			if !errors.Is(out.err, errInterrupted) {
+				err := files.Write(cache.MakePath(puzzles[out.i].key, files.LastCheck), []byte(puzzles[out.i].doc.Outcome))
+				if err != nil {
+					return fmt.Errorf("recording outcome: %v", err)
+				}
+			}
+			sum.add(out)
+			p := &puzzles[out.i]
+			if errors.Is(out.err, errTimeout) {
+				p.result, p.colour = "timeout", red
+			} else if out.err != nil {
+				p.result, p.colour = "error", red
+			} else if out.slow {
+				p.result, p.colour = "slow", red
 			} else if out.success {
-				puzzles[out.i].result = "\033[38;2;255;255;103m*\033[0m"
+				p.result, p.colour = "*", yellow
 			} else {
-				puzzles[out.i].result = "\033[38;2;255;0;0mx\033[0m"
+				p.result, p.colour = "x", red
+			}
+			if out.success {
+				p.timing = timing(out.run.before, out.run.res)
+			}
+			if !live && !c.json {
+				print(i, puzzles[out.i:out.i+1], spinner)
 			}
 		}
 
+		if !live {
+			continue
+		}
 		print(i, puzzles, spinner)
 		fmt.Printf("\033[%dA", len(puzzles))
 	}
 }
 
-func runnerRoutine(path string, i int, ch chan<- outcome, wg *sync.WaitGroup) {
+// summary counts the outcomes of checked puzzles.
+type summary struct {
+	passed int
+	slow   int
+	wrong  int
+	errors int // timeouts included
+	// slowGate tells whether puzzles may fail for being slow, which is then part of the summary.
+	slowGate bool
+}
+
+func (s *summary) add(out outcome) {
+	switch {
+	case out.err != nil:
+		s.errors++
+	case out.slow:
+		s.slow++
+	case out.success:
+		s.passed++
+	default:
+		s.wrong++
+	}
+}
+
+func (s summary) String() string {
+	if s.slowGate {
+		return fmt.Sprintf("%d passed, %d slow, %d wrong, %d errors", s.passed, s.slow, s.wrong, s.errors)
+	}
+
+	return fmt.Sprintf("%d passed, %d wrong, %d errors", s.passed, s.wrong, s.errors)
+}
+
+// err fails the check if any puzzle didn't pass.
+func (s summary) err() error {
+	if failed := s.slow + s.wrong + s.errors; failed > 0 {
+		return fmt.Errorf("%d of %d puzzles failed", failed, failed+s.passed)
+	}
+
+	return nil
+}
+
+func checkDoc(key cache.PuzzleKey, out outcome) puzzleDoc {
+	switch {
+	case errors.Is(out.err, errTimeout):
+		return failedDocs(key.Year, key.Day, key.Input, []int{key.Part}, outcomeTimeout)[0]
+	case out.err != nil:
+		return failedDocs(key.Year, key.Day, key.Input, []int{key.Part}, outcomeError)[0]
+	default:
+		doc := runDoc(key, out.run.before, out.run.res, nil)
+		if out.slow {
+			doc.Outcome = outcomeSlow
+		}
+		return doc
+	}
+}
+
+func checkDocs(puzzles []printable) []puzzleDoc {
+	docs := make([]puzzleDoc, 0, len(puzzles))
+	for _, p := range puzzles {
+		docs = append(docs, p.doc)
+	}
+
+	return docs
+}
+
+func runnerRoutine(ctx context.Context, env runEnv, key cache.PuzzleKey, i int, ch chan<- outcome, wg *sync.WaitGroup) {
 	defer wg.Done()
-	out, err := exec.BinaryAndCapture(path)
+	runs, err := env.runPuzzle(ctx, key.Year, key.Day, key.Input, key.Part)
 	if err != nil {
-		ch <- outcome{i, false, err}
-	} else if strings.Contains(string(out), "Error") {
-		ch <- outcome{i, false, nil}
-	} else {
-		ch <- outcome{i, true, nil}
+		ch <- outcome{i: i, err: err}
+		return
+	}
+	if len(runs) == 0 {
+		ch <- outcome{i: i, err: errors.New("runner reported no result")}
+		return
 	}
+
+	ch <- outcome{i: i, run: runs[0], success: runs[0].before.correct(runs[0].res)}
 }
 
 func print(i int, lines []printable, spinner string) {
 	for _, toPrint := range lines {
-		if toPrint.result == "" {
-			fmt.Printf("%-16s %s\n", toPrint.name, string(spinner[i%len(spinner)]))
+		result := toPrint.result
+		if result == "" {
+			result = string(spinner[i%len(spinner)])
+		}
+		if toPrint.timing == "" {
+			fmt.Printf("%-16s %s\n", toPrint.name, paint(toPrint.colour, result))
 		} else {
-			fmt.Printf("%-16s %s\n", toPrint.name, toPrint.result)
+			fmt.Printf("%-16s %s %s\n", toPrint.name, paint(toPrint.colour, fmt.Sprintf("%-7s", result)), toPrint.timing)
 		}
 	}
 }
+
+// timing compares the duration of a run of a locked puzzle with the best one before it.
+func timing(rec record, res result) string {
+	if rec.dur == math.MaxInt64 {
+		return res.Dur.String()
+	}
+
+	diff := res.Dur - rec.dur
+	return fmt.Sprintf("%v (best %v, %.0f%%)", res.Dur, rec.dur, (float64(diff)/float64(res.Dur))*100.0)
+}
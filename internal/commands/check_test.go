@@ -0,0 +1,165 @@
+package commands_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gombrii/aoc/internal/commands"
+	"github.com/gombrii/aoc/internal/config"
+)
+
+func TestCheck(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	cmd := commands.Commands{}
+	if err := cmd.Run(2024, 1, 1, "input.txt", commands.RunOpts{}); err != nil {
+		t.Fatalf("calling Run: %v", err)
+	}
+	if err := cmd.Lock(2024, 1, 1, "input.txt"); err != nil {
+		t.Fatalf("calling Lock: %v", err)
+	}
+
+	var err error
+	out := captureStdout(t, func() { err = cmd.Check(commands.CheckOpts{}) })
+	if err != nil {
+		t.Errorf("Check of correct puzzle returned error: %v", err)
+	}
+	if strings.Contains(string(out), "\033") {
+		t.Errorf("Check printed escape codes to a pipe:\n%q", out)
+	}
+	if !strings.Contains(string(out), "1 passed, 0 wrong, 0 errors") {
+		t.Errorf("Check didn't print summary:\n%s", out)
+	}
+
+	setAnswer(t, "something else")
+
+	out = captureStdout(t, func() { err = cmd.Check(commands.CheckOpts{}) })
+	if err == nil {
+		t.Error("Check of wrong puzzle didn't return an error")
+	}
+	if !strings.Contains(string(out), "0 passed, 1 wrong, 0 errors") {
+		t.Errorf("Check didn't print summary:\n%s", out)
+	}
+}
+
+func TestCheckFilters(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	cmd := commands.Commands{}
+	if err := cmd.Run(2024, 1, 1, "input.txt", commands.RunOpts{}); err != nil {
+		t.Fatalf("calling Run: %v", err)
+	}
+	if err := cmd.Lock(2024, 1, 1, "input.txt"); err != nil {
+		t.Fatalf("calling Lock: %v", err)
+	}
+	for _, step := range []struct {
+		name string
+		res  string
+		opts commands.CheckOpts
+		want string
+	}{
+		{"other year", "NOT IMPLEMENTED!", commands.CheckOpts{Year: 2023}, "0 passed, 0 wrong, 0 errors"},
+		{"other days", "NOT IMPLEMENTED!", commands.CheckOpts{FromDay: 2, ToDay: 10}, "0 passed, 0 wrong, 0 errors"},
+		{"other part", "NOT IMPLEMENTED!", commands.CheckOpts{Part: 2}, "0 passed, 0 wrong, 0 errors"},
+		{"other pattern", "NOT IMPLEMENTED!", commands.CheckOpts{Match: []string{"2024/day2*"}}, "0 passed, 0 wrong, 0 errors"},
+		{"pattern", "NOT IMPLEMENTED!", commands.CheckOpts{Match: []string{"2023/*", "2024/day1/*"}}, "1 passed, 0 wrong, 0 errors"},
+		{"failed when passed", "NOT IMPLEMENTED!", commands.CheckOpts{Failed: true}, "0 passed, 0 wrong, 0 errors"},
+		{"wrong", "something else", commands.CheckOpts{}, "0 passed, 1 wrong, 0 errors"},
+		{"failed when wrong", "NOT IMPLEMENTED!", commands.CheckOpts{Failed: true}, "1 passed, 0 wrong, 0 errors"},
+	} {
+		setAnswer(t, step.res)
+		out := captureStdout(t, func() { cmd.Check(step.opts) })
+		if !strings.Contains(string(out), step.want) {
+			t.Errorf("%s: want summary %q, got:\n%s", step.name, step.want, out)
+		}
+	}
+}
+
+func TestCheckSerialTiming(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	cmd := commands.Commands{}
+	for part := 1; part <= 2; part++ {
+		if err := cmd.Run(2024, 1, part, "input.txt", commands.RunOpts{}); err != nil {
+			t.Fatalf("calling Run: %v", err)
+		}
+		if err := cmd.Lock(2024, 1, part, "input.txt"); err != nil {
+			t.Fatalf("calling Lock: %v", err)
+		}
+	}
+
+	var err error
+	out := captureStdout(t, func() { err = cmd.Check(commands.CheckOpts{Jobs: 1, SerialTiming: true}) })
+	if err != nil {
+		t.Errorf("calling Check: %v", err)
+	}
+	if n := strings.Count(string(out), "(best "); n != 2 {
+		t.Errorf("got %d timed puzzles, want 2:\n%s", n, out)
+	}
+	if !strings.Contains(string(out), "2 passed, 0 wrong, 0 errors") {
+		t.Errorf("Check didn't print summary:\n%s", out)
+	}
+}
+
+func TestCheckSlow(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	cmd := commands.Commands{}
+	if err := cmd.Run(2024, 1, 1, "input.txt", commands.RunOpts{}); err != nil {
+		t.Fatalf("calling Run: %v", err)
+	}
+	if err := cmd.Lock(2024, 1, 1, "input.txt"); err != nil {
+		t.Fatalf("calling Lock: %v", err)
+	}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = cmd.Check(commands.CheckOpts{Slow: config.Threshold{Abs: time.Hour}})
+	})
+	if err != nil {
+		t.Errorf("Check of puzzle within threshold returned error: %v", err)
+	}
+	if !strings.Contains(string(out), "1 passed, 0 slow, 0 wrong, 0 errors") {
+		t.Errorf("Check didn't print summary:\n%s", out)
+	}
+
+	dur := filepath.Join(puzzleDir(t, "2024-day1-part1-input"), "dur")
+	if err := os.WriteFile(dur, []byte("1ns"), 0644); err != nil {
+		t.Fatalf("changing best duration: %v", err)
+	}
+
+	out = captureStdout(t, func() {
+		err = cmd.Check(commands.CheckOpts{Slow: config.Threshold{Percent: 20}})
+	})
+	if err == nil {
+		t.Error("Check of slow puzzle didn't return an error")
+	}
+	if !strings.Contains(string(out), "slow") || !strings.Contains(string(out), "0 passed, 1 slow, 0 wrong, 0 errors") {
+		t.Errorf("Check didn't report slow puzzle:\n%s", out)
+	}
+}
+
+// setAnswer changes the locked answer of 2024/day1/part1 with input.txt in the answers file.
+func setAnswer(t *testing.T, answer string) {
+	t.Helper()
+	data := fmt.Sprintf(`{"2024/day1/part1/input.txt": {"answer": %q}}`, answer)
+	if err := os.WriteFile(commands.AnswersFile, []byte(data), 0644); err != nil {
+		t.Fatalf("changing locked answer: %v", err)
+	}
+}
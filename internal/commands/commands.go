@@ -1,3 +1,5 @@
 package commands
 
-type Commands struct{}
+type Commands struct {
+	json bool // print JSON instead of text, see SetJSON
+}
@@ -1,6 +1,7 @@
 package commands_test
 
 import (
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
@@ -8,6 +9,8 @@ import (
 	"github.com/otiai10/copy"
 	"gotest.tools/v3/assert"
 	"gotest.tools/v3/fs"
+
+	"github.com/gombrii/aoc/internal/cache"
 )
 
 func prepare(t *testing.T) (testRoot, testCache, wd string) {
@@ -56,6 +59,17 @@ func initDay(t *testing.T, wd, testRoot string) {
 	}
 }
 
+// puzzleDir returns the cache of a puzzle of the module in the working directory, if any.
+func puzzleDir(t *testing.T, id string) string {
+	t.Helper()
+	key, err := cache.ParsePuzzleKey(id)
+	if err != nil {
+		t.Fatalf("parsing cache key: %v", err)
+	}
+
+	return cache.MakePath(key, "")
+}
+
 // initCache stores in testCache the structure
 //
 //	testCache/
@@ -74,3 +88,60 @@ func assertEqual(t *testing.T, wd, expectedDir, actualDir string) {
 	expected := fs.ManifestFromDir(t, expectedDir)
 	assert.Assert(t, fs.Equal(actualDir, expected))
 }
+
+// captureStdout returns what fn prints to stdout.
+func captureStdout(t *testing.T, fn func()) []byte {
+	t.Helper()
+	return capture(t, &os.Stdout, fn)
+}
+
+// captureStderr returns what fn prints to stderr.
+func captureStderr(t *testing.T, fn func()) []byte {
+	t.Helper()
+	return capture(t, &os.Stderr, fn)
+}
+
+func capture(t *testing.T, f **os.File, fn func()) []byte {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("creating pipe: %v", err)
+	}
+
+	orig := *f
+	*f = w
+	defer func() { *f = orig }()
+
+	out := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		out <- data
+	}()
+
+	fn()
+	w.Close()
+
+	return <-out
+}
+
+// writeFile writes data to the file at path, relative to the working directory.
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("creating dir of %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+}
+
+// cachedResult reads the result of a puzzle of the module in the working directory from cache.
+func cachedResult(t *testing.T, id string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(puzzleDir(t, id), "res"))
+	if err != nil {
+		t.Fatalf("reading result of %s: %v", id, err)
+	}
+
+	return string(data)
+}
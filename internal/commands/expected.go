@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gombrii/aoc/internal/files"
+)
+
+// expectedTmpl is the content of an expected answers file. It sits next to the input file it
+// belongs to, eg. test.expected for test.txt, and holds the known answer of each part.
+const expectedTmpl = `part1:{{ with .Answer1 }} {{ . }}{{ end }}
+part2:{{ with .Answer2 }} {{ . }}{{ end }}
+`
+
+func expectedPath(year, day int, input string) string {
+	name := strings.TrimSuffix(input, filepath.Ext(input)) + ".expected"
+	return filepath.Join(fmt.Sprint(year), "input", fmt.Sprintf("day%d", day), name)
+}
+
+// readExpected returns the expected answer of each part for input, keyed by part. Parts without a
+// known answer are left out.
+func readExpected(year, day int, input string) (map[int]string, error) {
+	path := expectedPath(year, day, input)
+	if !files.Exists(path) {
+		return nil, nil
+	}
+
+	data, err := files.Read(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading expected answers: %v", err)
+	}
+
+	expected := make(map[int]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		name, answer, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		var part int
+		if _, err := fmt.Sscanf(strings.TrimSpace(name), "part%d", &part); err != nil {
+			continue
+		}
+		if answer = strings.TrimSpace(answer); answer != "" {
+			expected[part] = answer
+		}
+	}
+
+	return expected, nil
+}
+
+func printExpected(expected map[int]string, res result) {
+	want, ok := expected[res.Part]
+	switch {
+	case !ok:
+	case want == res.Res:
+		fmt.Println("Exp: ✓")
+	default:
+		fmt.Printf("Exp: ✗ want %s\n", want)
+	}
+}
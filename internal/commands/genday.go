@@ -34,7 +34,7 @@ func (c Commands) GenDay(year, day int) error {
 	dName := fmt.Sprintf("day%d", day)
 
 	puzzleInput := ""
-	exampleInput := ""
+	example := com.Example{}
 
 	if session, ok := LoggedIn(); ok {
 		data, err := com.GetPuzzleInput(com.NewClient(session), year, day)
@@ -42,25 +42,30 @@ func (c Commands) GenDay(year, day int) error {
 			fmt.Println("Warning: failed to fetch puzzle input from server")
 		}
 		puzzleInput = data
-		data, err = com.GetExampleInput(com.NewClient(session), year, day)
+		example, err = com.GetExample(com.NewClient(session), year, day)
 		if err != nil {
 			fmt.Println("Warning: failed to fetch puzzle input from server")
 		}
-		exampleInput = data
 	}
 
+	answers := make([]string, 2)
+	copy(answers, example.Answers)
+
 	if err := files.Gen(
 		map[string]string{
 			filepath.Join(yName, "solutions", dName, "part1.go"):  part1Tmpl,
 			filepath.Join(yName, "solutions", dName, "part2.go"):  part2Tmpl,
 			filepath.Join(yName, "solutions", dName, "common.go"): commonTmpl,
 			filepath.Join(yName, "input", dName, "input.txt"):     puzzleInput,
-			filepath.Join(yName, "input", dName, "test.txt"):      exampleInput,
+			filepath.Join(yName, "input", dName, "test.txt"):      example.Input,
+			expectedPath(year, day, "test.txt"):                   expectedTmpl,
 		},
 		map[string]string{
 			"Year":    yName,
 			"Day":     fmt.Sprint(day),
 			"DayName": dName,
+			"Answer1": answers[0],
+			"Answer2": answers[1],
 		}); err != nil {
 		return fmt.Errorf("generating files: %v", err)
 	}
@@ -1,7 +1,11 @@
 package commands_test
 
 import (
+	"io"
+	"net/http"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gombrii/aoc/internal/commands"
@@ -31,3 +35,51 @@ func TestGenDayWithFilesPresent(t *testing.T) {
 
 	assertEqual(t, wd, testRoot, filepath.Join(wd, "testdata", "newday"))
 }
+
+// puzzleServer answers requests for the puzzle and input of 2024/day1 the way the server does.
+type puzzleServer struct{}
+
+func (puzzleServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, status := "", http.StatusNotFound
+	switch req.URL.Path {
+	case "/2024/day/1":
+		body, status = `<html><body><main><article class="day-desc"><pre><code>1 2 3</code></pre>
+<p>The answer is <code><em>6</em></code>.</p></article></main></body></html>`, http.StatusOK
+	case "/2024/day/1/input":
+		body, status = "4 5 6\n", http.StatusOK
+	}
+
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+}
+
+func TestGenDayLoggedIn(t *testing.T) {
+	_, testCache, _ := prepare(t)
+
+	transport := http.DefaultTransport
+	http.DefaultTransport = puzzleServer{}
+	t.Cleanup(func() { http.DefaultTransport = transport })
+
+	if err := os.MkdirAll(filepath.Join(testCache, "config", "user"), 0755); err != nil {
+		t.Fatalf("creating config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(testCache, "config", "user", "session"), []byte("secret"), 0644); err != nil {
+		t.Fatalf("logging in: %v", err)
+	}
+
+	if err := (commands.Commands{}).GenDay(2024, 1); err != nil {
+		t.Fatalf("calling GenDay: %v", err)
+	}
+
+	for path, want := range map[string]string{
+		"2024/input/day1/input.txt":     "4 5 6",
+		"2024/input/day1/test.txt":      "1 2 3",
+		"2024/input/day1/test.expected": "part1: 6\npart2:\n",
+	} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s wasn't generated: %v", path, err)
+		} else if string(data) != want {
+			t.Errorf("%s is %q, want %q", path, data, want)
+		}
+	}
+}
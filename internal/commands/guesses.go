@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/gombrii/aoc/internal/cache"
+	"github.com/gombrii/aoc/internal/files"
+)
+
+// guess is an answer submitted to the server, kept in cache one JSON document per line. Verdict is
+// the outcome of submitting it, eg. too high.
+type guess struct {
+	Time    time.Time `json:"time"`
+	Answer  string    `json:"answer"`
+	Verdict string    `json:"verdict"`
+}
+
+func recordGuess(key cache.PuzzleKey, answer, verdict string) error {
+	return appendLine(key, files.Guesses, guess{Time: time.Now(), Answer: answer, Verdict: verdict})
+}
+
+func readGuesses(key cache.PuzzleKey) ([]guess, error) {
+	return readLines[guess](key, files.Guesses)
+}
+
+// ruledOut tells why answer can't be right, judging by the guesses before it, if they tell. An
+// answer is ruled out if it was rejected before, or if it's a number beyond one that was too high or
+// too low.
+func ruledOut(answer string, guesses []guess) (string, bool) {
+	for _, g := range guesses {
+		rejected := g.Verdict == submitTooHigh || g.Verdict == submitTooLow || g.Verdict == submitWrong
+		if rejected && g.Answer == answer {
+			return fmt.Sprintf("%s was already submitted %s, and was %s", answer, g.Time.Local().Format(time.DateTime), g.Verdict), true
+		}
+	}
+
+	n, ok := new(big.Int).SetString(answer, 10)
+	if !ok {
+		return "", false
+	}
+	for _, g := range guesses {
+		bound, ok := new(big.Int).SetString(g.Answer, 10)
+		if !ok {
+			continue
+		}
+		switch {
+		case g.Verdict == submitTooHigh && n.Cmp(bound) >= 0:
+			return fmt.Sprintf("%s is at least %s, which was too high", answer, g.Answer), true
+		case g.Verdict == submitTooLow && n.Cmp(bound) <= 0:
+			return fmt.Sprintf("%s is at most %s, which was too low", answer, g.Answer), true
+		}
+	}
+
+	return "", false
+}
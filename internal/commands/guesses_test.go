@@ -0,0 +1,76 @@
+package commands_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gombrii/aoc/internal/commands"
+)
+
+func TestSubmitRuledOut(t *testing.T) {
+	testRoot, testCache, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	cmd := commands.Commands{}
+	if err := cmd.Run(2024, 1, 1, "input.txt", commands.RunOpts{}); err != nil {
+		t.Fatalf("calling Run: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(testCache, "config", "user"), 0755); err != nil {
+		t.Fatalf("creating config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(testCache, "config", "user", "session"), []byte("secret"), 0644); err != nil {
+		t.Fatalf("logging in: %v", err)
+	}
+
+	guesses := `{"time":"2024-12-01T06:01:02Z","answer":"NOT IMPLEMENTED!","verdict":"wrong"}` + "\n"
+	if err := os.WriteFile(filepath.Join(puzzleDir(t, "2024-day1-part1-input"), "guesses"), []byte(guesses), 0644); err != nil {
+		t.Fatalf("writing guesses: %v", err)
+	}
+
+	// The answer is refused before the prompt, so nothing is read from stdin.
+	err := cmd.Submit(commands.SubmitOpts{})
+	if err == nil || !strings.Contains(err.Error(), "already submitted") {
+		t.Errorf("Submit of an answer already rejected returned %v", err)
+	}
+
+	guesses = `{"time":"2024-12-01T06:01:02Z","answer":"100","verdict":"too high"}` + "\n"
+	if err := os.WriteFile(filepath.Join(puzzleDir(t, "2024-day1-part1-input"), "guesses"), []byte(guesses), 0644); err != nil {
+		t.Fatalf("writing guesses: %v", err)
+	}
+	err = cmd.Submit(commands.SubmitOpts{Year: 2024, Day: 1, Part: 1, Answer: "150", Yes: true})
+	if err == nil || !strings.Contains(err.Error(), "too high") {
+		t.Errorf("Submit of an answer above one too high returned %v", err)
+	}
+
+	out := captureStdout(t, func() { err = cmd.Status(2024, 1, 1, "input.txt") })
+	if err != nil {
+		t.Fatalf("calling Status: %v", err)
+	}
+	if !strings.Contains(string(out), "Guesses:") || !strings.Contains(string(out), "too high") {
+		t.Errorf("Status didn't list guesses:\n%s", out)
+	}
+}
+
+func TestSubmitNeverRun(t *testing.T) {
+	testRoot, testCache, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	if err := os.MkdirAll(filepath.Join(testCache, "config", "user"), 0755); err != nil {
+		t.Fatalf("creating config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(testCache, "config", "user", "session"), []byte("secret"), 0644); err != nil {
+		t.Fatalf("logging in: %v", err)
+	}
+
+	cmd := commands.Commands{}
+	err := cmd.Submit(commands.SubmitOpts{Year: 2024, Day: 1, Part: 2, Yes: true})
+	if err == nil || !strings.Contains(err.Error(), "no result") {
+		t.Errorf("Submit of a puzzle never run returned %v", err)
+	}
+}
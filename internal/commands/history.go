@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gombrii/aoc/internal/cache"
+	"github.com/gombrii/aoc/internal/exec"
+	"github.com/gombrii/aoc/internal/files"
+)
+
+// historyEntry is one run of a puzzle in its history, which is kept in cache one JSON document per
+// line. Durations are in nanoseconds.
+type historyEntry struct {
+	Time   time.Time     `json:"time"`
+	Res    string        `json:"result"`
+	Dur    time.Duration `json:"duration"`
+	Input  string        `json:"input_hash,omitempty"` // first bytes of the SHA-256 of the input
+	Commit string        `json:"commit,omitempty"`
+	Dirty  bool          `json:"dirty,omitempty"` // uncommitted changes on top of Commit
+}
+
+// vcsState is where the module stands in git, if it's in a repository.
+type vcsState struct {
+	commit string
+	dirty  bool
+}
+
+func currentVCS() vcsState {
+	out, err := exec.CommandAndCapture("git", "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return vcsState{}
+	}
+	state := vcsState{commit: string(bytes.TrimSpace(out))}
+
+	if out, err := exec.CommandAndCapture("git", "status", "--porcelain"); err == nil {
+		state.dirty = len(bytes.TrimSpace(out)) > 0
+	}
+
+	return state
+}
+
+// hashInput identifies the content of an input file, so that runs with different inputs by the
+// same name can be told apart. An unreadable file has no hash.
+func hashInput(path string) string {
+	data, err := files.Read(path)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:4])
+}
+
+func appendHistory(key cache.PuzzleKey, entry historyEntry) error {
+	return appendLine(key, files.History, entry)
+}
+
+func readHistory(key cache.PuzzleKey) ([]historyEntry, error) {
+	return readLines[historyEntry](key, files.History)
+}
+
+// appendLine appends v to a file of key kept one JSON document per line.
+func appendLine(key cache.PuzzleKey, file string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return cache.Append(key, file, append(data, '\n'))
+}
+
+// readLines reads a file of key kept one JSON document per line. A missing file has no lines.
+func readLines[T any](key cache.PuzzleKey, file string) ([]T, error) {
+	path, ok := cache.Contains(key, file)
+	if !ok {
+		return nil, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	lines := make([]T, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var line T
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, fmt.Errorf("parsing %s: %v", file, err)
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+// History prints the last runs of a puzzle, all of them if last is zero, with a sparkline of how
+// their durations changed over time.
+func (c Commands) History(year, day, part int, input string, last int) error {
+	key := cache.PuzzleKey{Year: year, Day: day, Part: part, Input: input}
+	entries, err := readHistory(key)
+	if err != nil {
+		return fmt.Errorf("reading history: %v", err)
+	}
+	if last > 0 && len(entries) > last {
+		entries = entries[len(entries)-last:]
+	}
+
+	if c.json {
+		return printJSON(entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("No history of running %d/day%d/part%d with %s\n", year, day, part, input)
+		return nil
+	}
+
+	durs := make([]time.Duration, 0, len(entries))
+	for _, e := range entries {
+		durs = append(durs, e.Dur)
+	}
+
+	fmt.Printf("%-19s  %-20s  %-14s  %-9s  %s\n", "TIME", "RESULT", "DURATION", "INPUT", "COMMIT")
+	for _, e := range entries {
+		commit := e.Commit
+		if e.Dirty {
+			commit += " (dirty)"
+		}
+		fmt.Printf("%-19s  %-20s  %-14v  %-9s  %s\n", e.Time.Local().Format(time.DateTime), e.Res, e.Dur, e.Input, commit)
+	}
+	fmt.Printf("\n%s  min %v, max %v\n", sparkline(durs), slices.Min(durs), slices.Max(durs))
+
+	return nil
+}
+
+// sparkline draws durations as bars, scaled from the shortest to the longest one.
+func sparkline(durs []time.Duration) string {
+	const bars = "▁▂▃▄▅▆▇█"
+	levels := []rune(bars)
+	lo, hi := slices.Min(durs), slices.Max(durs)
+
+	var b strings.Builder
+	for _, d := range durs {
+		i := 0
+		if hi > lo {
+			i = int(float64(d-lo) / float64(hi-lo) * float64(len(levels)-1))
+		}
+		b.WriteRune(levels[i])
+	}
+
+	return b.String()
+}
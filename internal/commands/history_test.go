@@ -0,0 +1,39 @@
+package commands_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gombrii/aoc/internal/commands"
+)
+
+func TestHistory(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	cmd := commands.Commands{}
+	out := captureStdout(t, func() { cmd.History(2024, 1, 1, "input.txt", 0) })
+	if !strings.Contains(string(out), "No history") {
+		t.Errorf("History of puzzle never run printed:\n%s", out)
+	}
+
+	for range 3 {
+		if err := cmd.Run(2024, 1, 1, "input.txt", commands.RunOpts{}); err != nil {
+			t.Fatalf("calling Run: %v", err)
+		}
+	}
+
+	var err error
+	out = captureStdout(t, func() { err = cmd.History(2024, 1, 1, "input.txt", 2) })
+	if err != nil {
+		t.Errorf("calling History: %v", err)
+	}
+	if n := strings.Count(string(out), "NOT IMPLEMENTED!"); n != 2 {
+		t.Errorf("got %d runs, want 2:\n%s", n, out)
+	}
+	if !strings.ContainsAny(string(out), "▁█") {
+		t.Errorf("History didn't print sparkline:\n%s", out)
+	}
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gombrii/aoc/internal/cache"
+	"github.com/gombrii/aoc/internal/files"
+)
+
+// stdinInput is the input that makes a puzzle read its input from stdin.
+const stdinInput = "-"
+
+// adHocPrefix marks the inputs of puzzles run with stdin or a file outside the input catalogue of
+// the day. Input files of the day can't start with it, so their records never collide.
+const adHocPrefix = "@"
+
+func isFileName(input string) bool {
+	return filepath.Base(input) == input
+}
+
+func adHoc(input string) bool {
+	return strings.HasPrefix(input, adHocPrefix)
+}
+
+// stageInput copies stdin, or the file at path, into the cache of the runner of a puzzle and
+// returns the ad-hoc input the puzzle is recorded under: "@stdin" for stdin and "@" followed by a
+// hash of the absolute path for a file, so that runs with the same file share a record.
+func stageInput(year, day int, path string, parts []int) (string, error) {
+	var input string
+	var data []byte
+	if path == stdinInput {
+		var err error
+		if data, err = io.ReadAll(os.Stdin); err != nil {
+			return "", fmt.Errorf("reading stdin: %v", err)
+		}
+		input = adHocPrefix + "stdin"
+	} else {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return "", fmt.Errorf("resolving input path: %v", err)
+		}
+		if !files.Exists(abs) {
+			return "", fmt.Errorf("input file %s does not exist", path)
+		}
+		if data, err = files.Read(abs); err != nil {
+			return "", fmt.Errorf("reading input file: %v", err)
+		}
+		sum := sha256.Sum256([]byte(abs))
+		input = adHocPrefix + hex.EncodeToString(sum[:4])
+	}
+
+	if _, err := cache.Write(runnerCacheKey(year, day, input, parts), files.Input, data); err != nil {
+		return "", fmt.Errorf("caching input: %v", err)
+	}
+
+	return input, nil
+}
+
+// inputPath returns the path of the file a runner cached under key reads input from.
+func inputPath(key cache.Key, year, day int, input string) string {
+	if adHoc(input) {
+		return cache.MakePath(key, files.Input)
+	}
+
+	return filepath.Join(fmt.Sprint(year), "input", fmt.Sprintf("day%d", day), input)
+}
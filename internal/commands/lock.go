@@ -2,8 +2,7 @@ package commands
 
 import (
 	"fmt"
-	"strconv"
-	"strings"
+	"time"
 
 	"github.com/gombrii/aoc/internal/cache"
 	"github.com/gombrii/aoc/internal/files"
@@ -11,32 +10,57 @@ import (
 
 func (c Commands) Status(year, day, part int, input string) error {
 	key := cache.PuzzleKey{Year: year, Day: day, Part: part, Input: input}
-	if _, exists := cache.ContainsKey(key); !exists {
+	if !hasRecord(key) {
+		if c.json {
+			return printJSON(noRecordDoc(key))
+		}
 		fmt.Printf("No record of running %d/day%d/part%d with %s\n", year, day, part, input)
 		return nil
 	}
 
-	data, err := files.ReadAll(map[string]string{
-		files.Lock: cache.MakePath(key, files.Lock),
-		files.Res:  cache.MakePath(key, files.Res),
-		files.Dur:  cache.MakePath(key, files.Dur),
-	})
+	rec, err := readRecord(key)
 	if err != nil {
 		return err
 	}
+	guesses, err := readGuesses(key)
+	if err != nil {
+		return fmt.Errorf("reading guesses: %v", err)
+	}
+	if c.json {
+		doc := recordDoc(key, rec)
+		doc.Guesses = guesses
+		return printJSON(doc)
+	}
 
-	locked, _ := strconv.ParseBool(strings.TrimSpace(data[files.Lock]))
-
-	if locked {
+	label := "Last"
+	if rec.locked {
+		label = "Best"
 		fmt.Printf(`▣ Locked
 Lock res: %s
-Best dur: %s
-`, data[files.Res], data[files.Dur])
+Best dur: %v
+`, rec.res, rec.dur)
 	} else {
 		fmt.Printf(`□ Unlocked
 Last res: %s
-Last dur: %s
-`, data[files.Res], data[files.Dur])
+Last dur: %v
+`, rec.res, rec.dur)
+	}
+
+	if rec.bench != 0 {
+		fmt.Printf("%s bench: %v\n", label, rec.bench)
+	}
+	if rec.alloc != 0 {
+		fmt.Printf("%s alloc: %s\n", label, byteSize(rec.alloc))
+	}
+	if rec.rss != 0 {
+		fmt.Printf("%s rss: %s\n", label, byteSize(rec.rss))
+	}
+
+	if len(guesses) > 0 {
+		fmt.Println("Guesses:")
+		for _, g := range guesses {
+			fmt.Printf("  %s  %-20s  %s\n", g.Time.Local().Format(time.DateTime), g.Answer, g.Verdict)
+		}
 	}
 
 	return nil
@@ -45,44 +69,86 @@ Last dur: %s
 func (c Commands) Lock(year, day, part int, input string) error {
 	key := cache.PuzzleKey{Year: year, Day: day, Part: part, Input: input}
 	if _, exists := cache.ContainsKey(key); !exists {
+		if c.json {
+			return printJSON(noRecordDoc(key))
+		}
 		fmt.Printf("No record of running %d/day%d/part%d with %s\n", year, day, part, input)
 		return nil
 	}
 
-	data, err := files.ReadAll(map[string]string{
-		files.Res: cache.MakePath(key, files.Res),
-		files.Dur: cache.MakePath(key, files.Dur),
-	})
+	rec, err := readRecord(key)
 	if err != nil {
 		return err
 	}
+	if err := lock(key, rec); err != nil {
+		return err
+	}
 
-	err = files.Write(cache.MakePath(key, files.Lock), []byte("true"))
-	if err != nil {
-		return fmt.Errorf("setting lock to true: %v", err)
+	if c.json {
+		rec.locked = true
+		return printJSON(recordDoc(key, rec))
 	}
 
 	fmt.Printf(`▣ Locked
 Lock res: %s
-Best dur: %s
-`, data[files.Res], data[files.Dur])
+Best dur: %v
+`, rec.res, rec.dur)
 
 	return nil
 }
 
 func (c Commands) Unlock(year, day, part int, input string) error {
 	key := cache.PuzzleKey{Year: year, Day: day, Part: part, Input: input}
-	if _, exists := cache.ContainsKey(key); !exists {
+	if !hasRecord(key) {
+		if c.json {
+			return printJSON(noRecordDoc(key))
+		}
 		fmt.Printf("No record of running %d/day%d/part%d with %s\n", year, day, part, input)
 		return nil
 	}
 
-	err := files.Write(cache.MakePath(key, files.Lock), []byte("false"))
-	if err != nil {
-		return fmt.Errorf("setting lock to false: %v", err)
+	if _, ok := cache.ContainsKey(key); ok {
+		err := files.Write(cache.MakePath(key, files.Lock), []byte("false"))
+		if err != nil {
+			return fmt.Errorf("setting lock to false: %v", err)
+		}
+	}
+	if err := unlockAnswer(key); err != nil {
+		return fmt.Errorf("removing answer: %v", err)
+	}
+
+	if c.json {
+		rec, err := readRecord(key)
+		if err != nil {
+			return err
+		}
+		return printJSON(recordDoc(key, rec))
 	}
 
 	fmt.Println(`□ Unlocked`)
 
 	return nil
 }
+
+// hasRecord tells whether anything is recorded about the puzzle of key, in cache or AnswersFile.
+func hasRecord(key cache.PuzzleKey) bool {
+	if _, ok := cache.ContainsKey(key); ok {
+		return true
+	}
+	ans, _ := readAnswers()
+	_, ok := ans[answerName(key)]
+
+	return ok
+}
+
+// lock locks the puzzle of key to the result of rec, both in cache and AnswersFile.
+func lock(key cache.PuzzleKey, rec record) error {
+	if err := files.Write(cache.MakePath(key, files.Lock), []byte("true")); err != nil {
+		return fmt.Errorf("setting lock to true: %v", err)
+	}
+	if err := lockAnswer(key, rec.res, rec.dur); err != nil {
+		return fmt.Errorf("recording answer: %v", err)
+	}
+
+	return nil
+}
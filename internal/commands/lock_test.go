@@ -3,6 +3,7 @@ package commands_test
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -15,7 +16,7 @@ func TestLock(t *testing.T) {
 
 	initCache(t, wd, testCache)
 
-	data, _ := os.ReadFile(filepath.Join(testCache, "puzzles", "2024-day1-part1-input", "lock"))
+	data, _ := os.ReadFile(filepath.Join(puzzleDir(t, "2024-day1-part1-input"), "lock"))
 	if strings.TrimSpace(string(data)) != "false" {
 		t.Error("lock didn't start as false")
 	}
@@ -24,7 +25,7 @@ func TestLock(t *testing.T) {
 		t.Errorf("calling Lock: %v", err)
 	}
 
-	data, _ = os.ReadFile(filepath.Join(testCache, "puzzles", "2024-day1-part1-input", "lock"))
+	data, _ = os.ReadFile(filepath.Join(puzzleDir(t, "2024-day1-part1-input"), "lock"))
 	if string(data) != "true" {
 		t.Error("locking didn't set lock to true")
 	}
@@ -33,12 +34,58 @@ func TestLock(t *testing.T) {
 		t.Errorf("calling Unlock: %v", err)
 	}
 
-	data, _ = os.ReadFile(filepath.Join(testCache, "puzzles", "2024-day1-part1-input", "lock"))
+	data, _ = os.ReadFile(filepath.Join(puzzleDir(t, "2024-day1-part1-input"), "lock"))
 	if string(data) != "false" {
 		t.Error("unlocking didn't set lock to false")
 	}
 }
 
+func TestStatusMemory(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	writeFile(t, "2024/solutions/day1/part1.go", `package day1
+
+var sink []byte
+
+func Part1(data []byte) any {
+	sink = make([]byte, 1<<20)
+	return len(sink)
+}
+`)
+	cmd := commands.Commands{}
+	if err := cmd.Run(2024, 1, 1, "input.txt", commands.RunOpts{}); err != nil {
+		t.Fatalf("calling Run: %v", err)
+	}
+	if err := cmd.Lock(2024, 1, 1, "input.txt"); err != nil {
+		t.Fatalf("calling Lock: %v", err)
+	}
+
+	names, lines := []string{"alloc"}, []string{"Best alloc"}
+	// RSS isn't measured on Windows.
+	if runtime.GOOS != "windows" {
+		names, lines = append(names, "rss"), append(lines, "Best rss")
+	}
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(puzzleDir(t, "2024-day1-part1-input"), name)); err != nil {
+			t.Errorf("%s wasn't cached: %v", name, err)
+		}
+	}
+
+	var err error
+	out := captureStdout(t, func() { err = cmd.Status(2024, 1, 1, "input.txt") })
+	if err != nil {
+		t.Fatalf("calling Status: %v", err)
+	}
+	for _, want := range lines {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("Status didn't print %s:\n%s", want, out)
+		}
+	}
+}
+
 func TestStatusNotExists(t *testing.T) {
 	_, _, _ = prepare(t)
 	if err := (commands.Commands{}).Lock(2024, 1, 1, "input.txt"); err != nil {
@@ -53,13 +100,13 @@ func TestCorruptFiles(t *testing.T) {
 	}{
 		"lock missing res": {
 			corrupt: func(testCache string) {
-				os.Remove(filepath.Join(testCache, "puzzles", "2024-day1-part1-input", "res"))
+				os.Remove(filepath.Join(puzzleDir(t, "2024-day1-part1-input"), "res"))
 			},
 			uut: (commands.Commands{}).Lock,
 		},
 		"status missing res": {
 			corrupt: func(testCache string) {
-				os.Remove(filepath.Join(testCache, "puzzles", "2024-day1-part1-input", "res"))
+				os.Remove(filepath.Join(puzzleDir(t, "2024-day1-part1-input"), "res"))
 			},
 			uut: (commands.Commands{}).Status,
 		},
@@ -77,3 +124,44 @@ func TestCorruptFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestLockAnswersFile(t *testing.T) {
+	testRoot, testCache, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	cmd := commands.Commands{}
+	if err := cmd.Run(2024, 1, 1, "input.txt", commands.RunOpts{}); err != nil {
+		t.Fatalf("calling Run: %v", err)
+	}
+	if err := cmd.Lock(2024, 1, 1, "input.txt"); err != nil {
+		t.Fatalf("calling Lock: %v", err)
+	}
+
+	data, _ := os.ReadFile(commands.AnswersFile)
+	if !strings.Contains(string(data), `"2024/day1/part1/input.txt"`) || !strings.Contains(string(data), `"answer": "NOT IMPLEMENTED!"`) {
+		t.Fatalf("Lock didn't record answer:\n%s", data)
+	}
+
+	// A fresh clone has nothing but the answers file.
+	if err := os.RemoveAll(testCache); err != nil {
+		t.Fatalf("clearing cache: %v", err)
+	}
+
+	var err error
+	out := captureStdout(t, func() { err = cmd.Check(commands.CheckOpts{}) })
+	if err != nil {
+		t.Errorf("Check from answers file returned error: %v", err)
+	}
+	if !strings.Contains(string(out), "1 passed, 0 wrong, 0 errors") {
+		t.Errorf("Check didn't check answers file:\n%s", out)
+	}
+
+	if err := cmd.Unlock(2024, 1, 1, "input.txt"); err != nil {
+		t.Fatalf("calling Unlock: %v", err)
+	}
+	if _, err := os.Stat(commands.AnswersFile); err == nil {
+		t.Error("Unlock didn't remove answer")
+	}
+}
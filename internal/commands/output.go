@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/gombrii/aoc/internal/cache"
+)
+
+// Outcomes of a puzzle in JSON output.
+const (
+	outcomeCorrect  = "correct"  // locked and reproduced the locked result
+	outcomeWrong    = "wrong"    // locked and produced another result
+	outcomeSlow     = "slow"     // locked and reproduced the locked result, but too slowly
+	outcomeUnlocked = "unlocked" // produced a result with nothing to verify it against
+	outcomeError    = "error"    // failed to compile, panicked or exited early
+	outcomeTimeout  = "timeout"
+	outcomeNoRecord = "no record" // never run
+)
+
+// puzzleDoc describes one part of a puzzle run with one input in JSON output. Durations are in
+// nanoseconds and sizes in bytes.
+type puzzleDoc struct {
+	Year      int     `json:"year"`
+	Day       int     `json:"day"`
+	Part      int     `json:"part"`
+	Input     string  `json:"input"`
+	Outcome   string  `json:"outcome,omitempty"`
+	Result    string  `json:"result"`
+	Expected  string  `json:"expected,omitempty"`
+	Duration  int64   `json:"duration,omitempty"`
+	Locked    bool    `json:"locked"`
+	LockedRes string  `json:"locked_result,omitempty"`
+	Best      int64   `json:"best_duration,omitempty"`
+	Alloc     uint64  `json:"alloc,omitempty"`
+	Mallocs   uint64  `json:"mallocs,omitempty"`
+	NumGC     uint32  `json:"gcs,omitempty"`
+	RSS       uint64  `json:"rss,omitempty"`
+	Median    int64   `json:"bench_median,omitempty"`
+	Guesses   []guess `json:"guesses,omitempty"` // answers submitted, only in status
+}
+
+// Outcomes of submitting an answer in JSON output, besides outcomeCorrect.
+const (
+	submitTooHigh     = "too high"
+	submitTooLow      = "too low"
+	submitWrong       = "wrong" // without a hint whether it's too high or too low
+	submitTooRecent   = "too recent"
+	submitWrongLevel  = "wrong level" // already solved, or part 1 not yet solved
+	submitNotUnlocked = "not unlocked"
+	submitCancelled   = "cancelled"
+)
+
+// submitDoc describes the submission of an answer in JSON output. Wait is how long, in nanoseconds,
+// the server asks to wait before submitting again.
+type submitDoc struct {
+	Year    int    `json:"year"`
+	Day     int    `json:"day"`
+	Part    int    `json:"part"`
+	Answer  string `json:"answer"`
+	Outcome string `json:"outcome"`
+	Wait    int64  `json:"wait,omitempty"`
+}
+
+// cacheDoc describes the cache of a puzzle in JSON output. Part is left out for the cache of both
+// parts of a day. Size is in bytes.
+type cacheDoc struct {
+	Year    int       `json:"year"`
+	Day     int       `json:"day"`
+	Part    int       `json:"part,omitempty"`
+	Input   string    `json:"input"`
+	Size    int64     `json:"size"`
+	Locked  bool      `json:"locked"`
+	LastRun time.Time `json:"last_run"`
+}
+
+// SetJSON makes commands print a single JSON document to stdout instead of text meant for people.
+// Output of puzzle solutions is then redirected to stderr.
+func (c *Commands) SetJSON(on bool) {
+	c.json = on
+}
+
+// solutionOut is where the output of puzzle solutions goes.
+func (c Commands) solutionOut() io.Writer {
+	if c.json {
+		return os.Stderr
+	}
+
+	return os.Stdout
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+// runDoc describes a run in JSON output. The record is the one before the run.
+func runDoc(key cache.PuzzleKey, rec record, res result, expected map[int]string) puzzleDoc {
+	doc := puzzleDoc{
+		Year:     key.Year,
+		Day:      key.Day,
+		Part:     key.Part,
+		Input:    key.Input,
+		Outcome:  outcomeUnlocked,
+		Result:   res.Res,
+		Expected: expected[key.Part],
+		Duration: int64(res.Dur),
+		Locked:   rec.locked,
+		Alloc:    res.Mem.TotalAlloc,
+		Mallocs:  res.Mem.Mallocs,
+		NumGC:    res.Mem.NumGC,
+		RSS:      res.RSS,
+	}
+	if rec.locked {
+		doc.LockedRes = rec.res
+		doc.Best = int64(rec.dur)
+		doc.Outcome = outcomeWrong
+		if rec.correct(res) {
+			doc.Outcome = outcomeCorrect
+		}
+	}
+	if res.Bench != nil {
+		doc.Median = int64(res.Bench.Median)
+	}
+
+	return doc
+}
+
+// recordDoc describes what is recorded about a puzzle in JSON output.
+func recordDoc(key cache.PuzzleKey, rec record) puzzleDoc {
+	doc := puzzleDoc{
+		Year:   key.Year,
+		Day:    key.Day,
+		Part:   key.Part,
+		Input:  key.Input,
+		Result: rec.res,
+		Locked: rec.locked,
+		Alloc:  rec.alloc,
+		RSS:    rec.rss,
+		Median: int64(rec.bench),
+	}
+	// A record starts out with the longest duration possible, to be beaten by the first run.
+	if rec.dur != math.MaxInt64 {
+		doc.Duration = int64(rec.dur)
+	}
+	if rec.locked {
+		doc.LockedRes = rec.res
+		doc.Best = doc.Duration
+	}
+
+	return doc
+}
+
+// failedDocs describes parts of a puzzle that failed to produce results in JSON output.
+func failedDocs(year, day int, input string, parts []int, outcome string) []puzzleDoc {
+	docs := make([]puzzleDoc, 0, len(parts))
+	for _, p := range parts {
+		key := cache.PuzzleKey{Year: year, Day: day, Part: p, Input: input}
+		rec, _ := readRecord(key)
+		doc := recordDoc(key, rec)
+		doc.Outcome, doc.Result, doc.Duration = outcome, "", 0
+		docs = append(docs, doc)
+	}
+
+	return docs
+}
+
+// noRecordDoc describes a puzzle that has never been run in JSON output.
+func noRecordDoc(key cache.PuzzleKey) puzzleDoc {
+	return puzzleDoc{Year: key.Year, Day: key.Day, Part: key.Part, Input: key.Input, Outcome: outcomeNoRecord}
+}
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/gombrii/aoc/internal/cache"
+	"github.com/gombrii/aoc/internal/exec"
+	"github.com/gombrii/aoc/internal/files"
+)
+
+// ProfileOpts picks which of the profiles of a puzzle to open. The CPU profile is opened unless
+// another one is asked for.
+type ProfileOpts struct {
+	Mem   bool
+	Trace bool
+}
+
+// profileFile is a profile asked for by RunOpts.
+type profileFile struct {
+	name string // of the file in cache
+	flag string // of the runner
+	dst  string // where to copy the profile after the run
+}
+
+func (opts RunOpts) profiles() []profileFile {
+	profiles := make([]profileFile, 0)
+	for _, p := range []profileFile{
+		{name: files.CPUProfile, flag: "cpuprofile", dst: opts.CPUProfile},
+		{name: files.MemProfile, flag: "memprofile", dst: opts.MemProfile},
+		{name: files.Trace, flag: "trace", dst: opts.Trace},
+	} {
+		if p.dst != "" {
+			profiles = append(profiles, p)
+		}
+	}
+
+	return profiles
+}
+
+// copyProfiles copies the profiles a runner left in the cache of key to where they were asked for
+// and returns the paths of the copies.
+func copyProfiles(key cache.PuzzleKey, profiles []profileFile) ([]string, error) {
+	written := make([]string, 0, len(profiles))
+	for _, p := range profiles {
+		path, ok := cache.Contains(key, p.name)
+		if !ok {
+			continue
+		}
+
+		data, err := files.Read(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading profile: %v", err)
+		}
+		if err := files.Write(p.dst, data); err != nil {
+			return nil, fmt.Errorf("writing profile: %v", err)
+		}
+		written = append(written, p.dst)
+	}
+
+	return written, nil
+}
+
+// Profile opens the last profile taken of a puzzle in go tool pprof, or go tool trace for traces.
+func (c Commands) Profile(year, day, part int, input string, opts ProfileOpts) error {
+	key := cache.PuzzleKey{Year: year, Day: day, Part: part, Input: input}
+
+	name, flag, tool := files.CPUProfile, "cpuprofile", "pprof"
+	switch {
+	case opts.Mem:
+		name, flag = files.MemProfile, "memprofile"
+	case opts.Trace:
+		name, flag, tool = files.Trace, "trace", "trace"
+	}
+
+	path, ok := cache.Contains(key, name)
+	if !ok {
+		fmt.Printf("No %s of %d/day%d/part%d with %s, run it with -%s first\n", name, year, day, part, input, flag)
+		return nil
+	}
+
+	return exec.CommandInteractive("go", "tool", tool, path)
+}
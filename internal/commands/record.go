@@ -0,0 +1,251 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gombrii/aoc/internal/cache"
+	"github.com/gombrii/aoc/internal/files"
+)
+
+// result is what a runner reports back after running one part of a puzzle.
+type result struct {
+	Part  int
+	Res   string
+	Dur   time.Duration
+	Mem   memStats
+	Bench *benchmark
+	RSS   uint64 `json:"-"` // peak resident set size of the runner in bytes, zero if unknown
+}
+
+// memStats is the difference in runtime.MemStats over a run.
+type memStats struct {
+	TotalAlloc uint64
+	Mallocs    uint64
+	NumGC      uint32
+}
+
+// benchmark is the outcome of running one part of a puzzle repeatedly.
+type benchmark struct {
+	N           int
+	Min         time.Duration
+	Median      time.Duration
+	Mean        time.Duration
+	P95         time.Duration
+	AllocsPerOp uint64
+	BytesPerOp  uint64
+}
+
+// record is what is remembered in cache about a puzzle. Apart from the result, the measurements are
+// the last ones of an unlocked puzzle and the best ones of a locked puzzle. Measurements never
+// taken are zero.
+type record struct {
+	locked bool
+	res    string
+	dur    time.Duration
+	bench  time.Duration // median of benchmark
+	alloc  uint64        // bytes allocated
+	rss    uint64        // peak resident set size in bytes
+}
+
+func (r record) correct(res result) bool {
+	return res.Res == r.res
+}
+
+func ensureRecord(key cache.PuzzleKey) error {
+	if _, ok := cache.Contains(key, files.Lock); ok {
+		return nil
+	}
+
+	fPaths, err := files.GenTemp(map[string]string{
+		files.Lock: strconv.FormatBool(false),
+		files.Res:  "",
+		files.Dur:  time.Duration(math.MaxInt64).String(),
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("generating files: %v", err)
+	}
+
+	for name, path := range fPaths {
+		if _, err := cache.Store(key, name, path); err != nil {
+			return fmt.Errorf("caching files: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// readRecord reads the record of key from cache and AnswersFile, where an answer recorded locks the
+// puzzle no matter what the cache says.
+func readRecord(key cache.PuzzleKey) (record, error) {
+	ans, err := readAnswers()
+	if err != nil {
+		return record{}, err
+	}
+	a, answered := ans[answerName(key)]
+
+	data := map[string]string{files.Dur: time.Duration(math.MaxInt64).String()}
+	if _, ok := cache.ContainsKey(key); ok || !answered {
+		data, err = files.ReadAll(map[string]string{
+			files.Lock: cache.MakePath(key, files.Lock),
+			files.Res:  cache.MakePath(key, files.Res),
+			files.Dur:  cache.MakePath(key, files.Dur),
+		})
+		if err != nil {
+			return record{}, err
+		}
+	}
+
+	for _, name := range []string{files.Bench, files.Alloc, files.RSS} {
+		if path, ok := cache.Contains(key, name); ok {
+			bytes, err := files.Read(path)
+			if err != nil {
+				return record{}, err
+			}
+			data[name] = string(bytes)
+		}
+	}
+
+	locked, _ := strconv.ParseBool(strings.TrimSpace(data[files.Lock]))
+	dur, _ := time.ParseDuration(strings.TrimSpace(data[files.Dur]))
+	bench, _ := time.ParseDuration(strings.TrimSpace(data[files.Bench]))
+	alloc, _ := strconv.ParseUint(strings.TrimSpace(data[files.Alloc]), 10, 64)
+	rss, _ := strconv.ParseUint(strings.TrimSpace(data[files.RSS]), 10, 64)
+
+	rec := record{
+		locked: locked,
+		res:    strings.TrimSpace(data[files.Res]),
+		dur:    dur,
+		bench:  bench,
+		alloc:  alloc,
+		rss:    rss,
+	}
+	if answered {
+		rec.locked, rec.res = true, a.Answer
+		if best := time.Duration(a.Best); best != 0 && best < rec.dur {
+			rec.dur = best
+		}
+	}
+
+	return rec, nil
+}
+
+// update records res in the cache of key, the same way for every kind of run. A locked puzzle only
+// remembers its best measurements, an unlocked one its last result and measurements. The record as
+// it was before the update is returned.
+func update(key cache.PuzzleKey, res result) (record, error) {
+	rec, err := readRecord(key)
+	if err != nil {
+		return record{}, fmt.Errorf("reading record: %v", err)
+	}
+
+	if rec.locked && !rec.correct(res) {
+		return rec, nil
+	}
+
+	writes := make(map[string]any)
+	keep := func(name string, better bool, value any) {
+		if !rec.locked || better {
+			writes[name] = value
+		}
+	}
+
+	keep(files.Res, false, res.Res)
+	keep(files.Dur, res.Dur < rec.dur, res.Dur)
+	keep(files.Alloc, rec.alloc == 0 || res.Mem.TotalAlloc < rec.alloc, res.Mem.TotalAlloc)
+	if res.Bench != nil {
+		keep(files.Bench, rec.bench == 0 || res.Bench.Median < rec.bench, res.Bench.Median)
+	}
+	if res.RSS != 0 {
+		keep(files.RSS, rec.rss == 0 || res.RSS < rec.rss, res.RSS)
+	}
+
+	for name, value := range writes {
+		if err := files.Write(cache.MakePath(key, name), []byte(fmt.Sprint(value))); err != nil {
+			return record{}, fmt.Errorf("writing record: %v", err)
+		}
+	}
+	if rec.locked && res.Dur < rec.dur {
+		if err := improveBest(key, res.Dur); err != nil {
+			return record{}, fmt.Errorf("writing record: %v", err)
+		}
+	}
+
+	return rec, nil
+}
+
+func printResult(rec record, res result) {
+	switch {
+	case rec.locked && !rec.correct(res):
+		fmt.Printf("Error: res: %v, want %v\n", res.Res, rec.res)
+		return
+	case rec.locked:
+		diff := res.Dur - rec.dur
+		fmt.Println("Res:", res.Res)
+		fmt.Printf("Dur: %v (%v, %.0f%%)\n", res.Dur, diff, (float64(diff)/float64(res.Dur))*100.0)
+	default:
+		fmt.Println("Res:", res.Res)
+		fmt.Println("Dur:", res.Dur)
+	}
+
+	m := res.Mem
+	if rec.locked && rec.alloc != 0 {
+		fmt.Printf("Mem: %s alloc (best %s), %d mallocs, %d GCs\n", byteSize(m.TotalAlloc), byteSize(rec.alloc), m.Mallocs, m.NumGC)
+	} else {
+		fmt.Printf("Mem: %s alloc, %d mallocs, %d GCs\n", byteSize(m.TotalAlloc), m.Mallocs, m.NumGC)
+	}
+	if res.RSS != 0 && rec.locked && rec.rss != 0 {
+		fmt.Printf("RSS: %s (best %s)\n", byteSize(res.RSS), byteSize(rec.rss))
+	} else if res.RSS != 0 {
+		fmt.Printf("RSS: %s\n", byteSize(res.RSS))
+	}
+
+	if b := res.Bench; b != nil {
+		fmt.Printf("Bench: %d runs, %d allocs/op, %d B/op\n", b.N, b.AllocsPerOp, b.BytesPerOp)
+		fmt.Printf("  min %v, median %v, mean %v, p95 %v\n", b.Min, b.Median, b.Mean, b.P95)
+		if rec.locked && rec.correct(res) && rec.bench != 0 {
+			diff := b.Median - rec.bench
+			fmt.Printf("  median vs best: %v (%v, %.0f%%)\n", rec.bench, diff, (float64(diff)/float64(b.Median))*100.0)
+		}
+	}
+}
+
+// readReport reads the results a runner left behind in its report file.
+func readReport(key cache.Key) ([]result, error) {
+	path, ok := cache.Contains(key, files.Report)
+	if !ok {
+		return nil, nil
+	}
+
+	data, err := files.Read(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading report: %v", err)
+	}
+
+	var results []result
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("parsing report: %v", err)
+	}
+
+	return results, nil
+}
+
+// byteSize formats n bytes in the largest binary unit that keeps it above one.
+func byteSize(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -2,15 +2,23 @@ package commands
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
-	"math"
+	"io"
+	"os"
+	"os/signal"
+	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"text/tabwriter"
 	"time"
 
 	"github.com/gombrii/aoc/internal/cache"
+	"github.com/gombrii/aoc/internal/config"
 	"github.com/gombrii/aoc/internal/exec"
 	"github.com/gombrii/aoc/internal/files"
 	"golang.org/x/mod/modfile"
@@ -19,45 +27,196 @@ import (
 const runnerTmpl = `package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
-	"time"
 	"os"
-	"strconv"
-	"strings"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"slices"
+	"time"
 
 	"{{ .PkgPath }}"
 )
 
+var (
+	bench     = flag.Bool("bench", false, "benchmark the parts after running them")
+	benchTime = flag.Duration("benchtime", time.Second, "time budget of a benchmark")
+	benchN    = flag.Int("benchn", 0, "number of iterations of a benchmark, overrides -benchtime")
+	cpuProf   = flag.String("cpuprofile", "", "write a CPU profile of the parts to this file")
+	memProf   = flag.String("memprofile", "", "write an allocation profile of the parts to this file")
+	traceOut  = flag.String("trace", "", "write an execution trace of the parts to this file")
+)
+
+type result struct {
+	Part  int
+	Res   string
+	Dur   time.Duration
+	Mem   memStats
+	Bench *benchmark
+}
+
+type memStats struct {
+	TotalAlloc uint64
+	Mallocs    uint64
+	NumGC      uint32
+}
+
+type benchmark struct {
+	N           int
+	Min         time.Duration
+	Median      time.Duration
+	Mean        time.Duration
+	P95         time.Duration
+	AllocsPerOp uint64
+	BytesPerOp  uint64
+}
+
 func main() {
-	locked, _ := strconv.ParseBool(strings.TrimSpace(string(read("{{ .LockPath }}"))))
-	record, _ := time.ParseDuration(strings.TrimSpace(string(read("{{ .DurPath }}"))))
-	lastRes := strings.TrimSpace(string(read("{{ .ResPath }}")))
+	flag.Parse()
+
 	data := read("{{ .InputPath }}")
-	
+	results := make([]result, 0, {{ len .Parts }})
+	// Every part gets an input of its own, so that changes one part makes to it can't leak into the
+	// next one.
+{{ range .Parts }}
+	results = append(results, run({{ .Num }}, {{ $.PkgName }}.{{ .FuncName }}, slices.Clone(data)))
+	write("{{ $.ReportPath }}", results)
+{{ end }}
+}
+
+func run(part int, fn func([]byte) any, data []byte) result {
+	var orig []byte
+	if *bench {
+		orig = slices.Clone(data)
+	}
+
+	stop := profile()
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
 	start := time.Now()
-	result := {{ .PkgName }}.{{ .FuncName }}(data)	
+	res := fn(data)
 	duration := time.Since(start)
 
-	correct := fmt.Sprint(result) == lastRes
-	switch {
-	case locked && !correct:
-		fmt.Printf("Error: res: %v, want %v\n", result, lastRes)
-		return
-	case locked && correct:
-		diff := duration - record
-		fmt.Println("Res:", result)
-		fmt.Printf("Dur: %v (%v, %.0f%%)\n", duration, diff, (float64(diff)/float64(duration))*100.0)
-		if diff < 0 {
-			write("{{ .DurPath }}", fmt.Sprint(duration))
-		}
-	case !locked:
-		fmt.Println("Res:", result)
-		fmt.Println("Dur:", duration)
-		write("{{ .ResPath }}", fmt.Sprint(result))
-		write("{{ .DurPath }}", fmt.Sprint(duration))
-	}
-}
-	
+	runtime.ReadMemStats(&after)
+	stop()
+
+	r := result{Part: part, Res: fmt.Sprint(res), Dur: duration, Mem: memStats{
+		TotalAlloc: after.TotalAlloc - before.TotalAlloc,
+		Mallocs:    after.Mallocs - before.Mallocs,
+		NumGC:      after.NumGC - before.NumGC,
+	}}
+	if *bench {
+		r.Bench = measure(fn, orig, duration)
+	}
+
+	return r
+}
+
+// measure runs fn repeatedly, every time on a fresh copy of the input, after the first run of the
+// part has warmed it up. The duration of the warm-up run estimates how many runs to make room for,
+// so that recording them doesn't add to the allocations.
+func measure(fn func([]byte) any, orig []byte, warmUp time.Duration) *benchmark {
+	buf := make([]byte, len(orig))
+	n := *benchN
+	if n <= 0 {
+		n = min(int(*benchTime/max(warmUp, time.Microsecond))+1, 1<<20)
+	}
+	durs := make([]time.Duration, 0, n)
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	budget := time.Now().Add(*benchTime)
+	for (*benchN > 0 && len(durs) < *benchN) || (*benchN <= 0 && (len(durs) == 0 || time.Now().Before(budget))) {
+		copy(buf, orig)
+		start := time.Now()
+		fn(buf)
+		durs = append(durs, time.Since(start))
+	}
+
+	runtime.ReadMemStats(&after)
+
+	slices.Sort(durs)
+	var total time.Duration
+	for _, d := range durs {
+		total += d
+	}
+	n = len(durs)
+
+	return &benchmark{
+		N:           n,
+		Min:         durs[0],
+		Median:      durs[n/2],
+		Mean:        total / time.Duration(n),
+		P95:         durs[(n*95+99)/100-1],
+		AllocsPerOp: (after.Mallocs - before.Mallocs) / uint64(n),
+		BytesPerOp:  (after.TotalAlloc - before.TotalAlloc) / uint64(n),
+	}
+}
+
+// profile starts the profiles asked for and returns a function that stops them and writes them to
+// their files.
+func profile() (stop func()) {
+	stops := make([]func(), 0)
+
+	if *cpuProf != "" {
+		f := create(*cpuProf)
+		if err := pprof.StartCPUProfile(f); err != nil {
+			fmt.Printf("Error: could not start CPU profile: %v\n", err)
+			os.Exit(1)
+		}
+		stops = append(stops, func() {
+			pprof.StopCPUProfile()
+			f.Close()
+		})
+	}
+
+	if *traceOut != "" {
+		f := create(*traceOut)
+		if err := trace.Start(f); err != nil {
+			fmt.Printf("Error: could not start trace: %v\n", err)
+			os.Exit(1)
+		}
+		stops = append(stops, func() {
+			trace.Stop()
+			f.Close()
+		})
+	}
+
+	if *memProf != "" {
+		stops = append(stops, func() {
+			f := create(*memProf)
+			runtime.GC()
+			if err := pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+				fmt.Printf("Error: could not write memory profile: %v\n", err)
+				os.Exit(1)
+			}
+			f.Close()
+		})
+	}
+
+	return func() {
+		for _, stop := range stops {
+			stop()
+		}
+	}
+}
+
+func create(path string) *os.File {
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Printf("Error: could not create file: %v\n", err)
+		os.Exit(1)
+	}
+
+	return f
+}
+
 func read(path string) []byte {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -68,94 +227,544 @@ func read(path string) []byte {
 	return data
 }
 
-func write(path string, data string) {
-	if err := os.WriteFile(path, []byte(data), 0755); err != nil {
+func write(path string, results []result) {
+	data, _ := json.Marshal(results)
+	if err := os.WriteFile(path, data, 0755); err != nil {
 		fmt.Printf("Error: could not write file: %v\n", err)
 		os.Exit(1)
 	}
 }
 `
 
-func (c Commands) Run(year, day, part int, input string) error {
-	if !files.Exists("go.mod") {
-		return errors.New("not in Go module root (no go.mod found)")
+const allInputs = "all"
+
+var (
+	errTimeout     = errors.New("timed out")
+	errInterrupted = errors.New("interrupted")
+)
+
+// RunOpts holds the optional ways of running a puzzle.
+type RunOpts struct {
+	// Bench benchmarks each part after running it.
+	Bench bool
+	// BenchTime is the time budget of a benchmark, eg. "2s", or a fixed number of iterations, eg.
+	// "100x". Defaults to 1s.
+	BenchTime string
+	// Timeout is the time the puzzle may run before it's killed. Defaults to the timeout of the
+	// project config, if any.
+	Timeout time.Duration
+	// Build holds options of the go toolchain. Each defaults to the project config, if any.
+	Build BuildOpts
+	// CPUProfile, MemProfile and Trace are files to write a CPU profile, an allocation profile and an
+	// execution trace of the run to. The last ones of each puzzle are also kept in cache.
+	CPUProfile string
+	MemProfile string
+	Trace      string
+	// Submit submits the result of the puzzle after running it, asking for confirmation first.
+	Submit bool
+}
+
+// args translates opts into arguments for a runner.
+func (opts RunOpts) args() ([]string, error) {
+	args := make([]string, 0)
+
+	if opts.Bench {
+		args = append(args, "-bench")
+		switch bt := opts.BenchTime; {
+		case bt == "":
+		case strings.HasSuffix(bt, "x"):
+			n, err := strconv.Atoi(strings.TrimSuffix(bt, "x"))
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid benchtime %q", bt)
+			}
+			args = append(args, fmt.Sprintf("-benchn=%d", n))
+		default:
+			d, err := time.ParseDuration(bt)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("invalid benchtime %q", bt)
+			}
+			args = append(args, fmt.Sprintf("-benchtime=%v", d))
+		}
+	}
+
+	return args, nil
+}
+
+// runEnv is what's needed to run puzzles of the current module in a certain way.
+type runEnv struct {
+	mod     string
+	build   []string // flags of go build
+	args    []string // passed on to runners
+	timeout time.Duration
+	out     io.Writer       // where the output of solutions goes, nil to discard it
+	builds  chan struct{}   // slots for building runners at the same time, unbounded if nil
+	runs    chan struct{}   // slots for running runners at the same time, unbounded if nil
+	vcs     func() vcsState // where the module stands in git, recorded in the history of runs
+	// buildErrs reports the compiler errors of a runner that failed to build, nil to discard them.
+	buildErrs func(output []byte)
+}
+
+// reportBuild returns a buildErrs that writes compiler errors to stderr, only once, as every input
+// of a puzzle is built from the same source.
+func reportBuild() func(output []byte) {
+	var once sync.Once
+	return func(output []byte) {
+		once.Do(func() { os.Stderr.Write(output) })
+	}
+}
+
+// acquire waits for a free slot among slots, unless ctx is done first. The returned function frees
+// the slot again.
+func acquire(ctx context.Context, slots chan struct{}) (release func(), ok bool) {
+	if slots == nil {
+		return func() {}, true
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, true
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+// puzzleRun is the outcome of running one part of a puzzle with one input.
+type puzzleRun struct {
+	key    cache.PuzzleKey
+	before record
+	res    result
+}
+
+// Run runs a part of a puzzle with the given input. A part of 0 runs both parts of the puzzle, one
+// after the other, on the same input. An input of "all" runs the puzzle with every input file of
+// the day and prints the outcomes as a table.
+func (c Commands) Run(year, day, part int, input string, opts RunOpts) error {
+	env, parts, name, err := prepareRun(year, day, part, opts)
+	if err != nil {
+		return err
 	}
 
 	yName := fmt.Sprintf("%d", year)
 	dName := fmt.Sprintf("day%d", day)
-	pName := fmt.Sprintf("part%d", part)
 
-	if !files.Exists(filepath.Join(yName, "solutions", dName, fmt.Sprintf("%s.go", pName))) {
-		return fmt.Errorf("%s does not exist", filepath.Join(yName, dName, pName))
-	}
-	if !files.Exists(filepath.Join(yName, "input", dName, input)) {
+	label := input
+	switch {
+	case input == allInputs:
+	case input == stdinInput || !isFileName(input):
+		if input == stdinInput {
+			label = "stdin"
+		}
+		if input, err = stageInput(year, day, input, parts); err != nil {
+			return err
+		}
+	case !files.Exists(filepath.Join(yName, "input", dName, input)):
 		return fmt.Errorf("input file %s does not exist for %s", input, filepath.Join(yName, dName))
 	}
 
-	fmt.Printf("Running %s with %s\n", filepath.Join(yName, dName, pName), input)
+	profiles := opts.profiles()
+	if len(profiles) > 0 && (part == 0 || input == allInputs) {
+		return errors.New("profiling requires a single part and input")
+	}
+	var session string
+	if opts.Submit {
+		if part == 0 || input != "input.txt" {
+			return errors.New("submitting requires a single part run with input.txt")
+		}
+		if c.json {
+			return errors.New("submitting after running isn't supported with JSON output")
+		}
+		var ok bool
+		if session, ok = LoggedIn(); !ok {
+			return errors.New("no logged in user")
+		}
+	}
+	profKey := cache.PuzzleKey{Year: year, Day: day, Part: part, Input: input}
+	for _, p := range profiles {
+		if err := cache.Remove(profKey, p.name); err != nil {
+			return fmt.Errorf("removing old profile: %v", err)
+		}
+		env.args = append(env.args, fmt.Sprintf("-%s=%s", p.flag, cache.MakePath(profKey, p.name)))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if input == allInputs {
+		return c.runAllInputs(ctx, env, name, year, day, parts)
+	}
 
-	path, err := getRunnerPath(year, day, part, input)
+	if !c.json {
+		fmt.Printf("Running %s with %s\n", name, label)
+	}
+
+	env.out = c.solutionOut()
+	runs, err := env.runPuzzle(ctx, year, day, input, parts...)
+	if errors.Is(err, errTimeout) {
+		if c.json {
+			printJSON(failedDocs(year, day, input, parts, outcomeTimeout))
+		}
+		return fmt.Errorf("%s timed out after %v", name, env.timeout)
+	}
 	if err != nil {
-		return fmt.Errorf("setting up runner: %v", err)
+		return err
 	}
 
-	if err = exec.BinaryAndPrint(path); err != nil {
-		return fmt.Errorf("executing runner: %v", err)
+	expected, err := readExpected(year, day, input)
+	if err != nil {
+		return err
 	}
 
-	setLastRun(cache.PuzzleKey{Year: year, Day: day, Part: part, Input: input})
+	docs := make([]puzzleDoc, 0, len(runs))
+	for _, r := range runs {
+		setLastRun(r.key)
+		if c.json {
+			docs = append(docs, runDoc(r.key, r.before, r.res, expected))
+			continue
+		}
+		if len(parts) > 1 {
+			fmt.Printf("Part %d\n", r.res.Part)
+		}
+		printResult(r.before, r.res)
+		printExpected(expected, r.res)
+	}
+
+	written, err := copyProfiles(profKey, profiles)
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		if len(runs) == 0 {
+			docs = failedDocs(year, day, input, parts, outcomeError)
+		}
+		return printJSON(docs)
+	}
+	for _, path := range written {
+		fmt.Println("Wrote", path)
+	}
+
+	if opts.Submit {
+		return c.submitRun(session, runs)
+	}
 
 	return nil
 }
 
-func getRunnerPath(year, day, part int, input string) (string, error) {
-	cacheKey := cache.PuzzleKey{Year: year, Day: day, Part: part, Input: input}
+// submitRun submits the result of a run of a single part, unless it's already locked.
+func (c Commands) submitRun(session string, runs []puzzleRun) error {
+	if len(runs) == 0 || runs[0].res.Res == "" {
+		return errors.New("no result to submit")
+	}
+	r := runs[0]
+	if r.before.locked {
+		fmt.Printf("\n%d/day%d/part%d is already locked in, not submitting.\n", r.key.Year, r.key.Day, r.key.Part)
+		return nil
+	}
+	fmt.Println()
+
+	return c.submit(session, r.key, r.res.Res, false)
+}
+
+// prepareRun checks that the parts of a puzzle exist and sets up the environment to run them in.
+// It returns the parts to run, expanding part 0 to both parts, and the name of the puzzle.
+func prepareRun(year, day, part int, opts RunOpts) (env runEnv, parts []int, name string, err error) {
+	if !files.Exists("go.mod") {
+		return runEnv{}, nil, "", errors.New("not in Go module root (no go.mod found)")
+	}
 
-	if cPath, ok := cache.Contains(cacheKey, files.Runner); ok {
-		return cPath, nil
+	cfg, err := config.Load()
+	if err != nil {
+		return runEnv{}, nil, "", err
 	}
 
-	mod, err := currentModulePath()
+	args, err := opts.args()
 	if err != nil {
-		return "", fmt.Errorf("getting module name: %v", err)
+		return runEnv{}, nil, "", err
+	}
+
+	parts = []int{part}
+	if part == 0 {
+		parts = []int{1, 2}
 	}
 
 	yName := fmt.Sprintf("%d", year)
 	dName := fmt.Sprintf("day%d", day)
-	pName := fmt.Sprintf("part%d", part)
+
+	for _, p := range parts {
+		pName := fmt.Sprintf("part%d", p)
+		if !files.Exists(filepath.Join(yName, "solutions", dName, fmt.Sprintf("%s.go", pName))) {
+			return runEnv{}, nil, "", fmt.Errorf("%s does not exist", filepath.Join(yName, dName, pName))
+		}
+	}
+
+	name = filepath.Join(yName, dName, fmt.Sprintf("part%d", part))
+	if part == 0 {
+		name = filepath.Join(yName, dName)
+	}
+
+	mod, err := currentModulePath()
+	if err != nil {
+		return runEnv{}, nil, "", fmt.Errorf("getting module name: %v", err)
+	}
+
+	env = runEnv{mod: mod, build: opts.Build.orConfig(cfg.Build).flags(), args: args, timeout: opts.Timeout, vcs: sync.OnceValue(currentVCS), buildErrs: reportBuild()}
+	if env.timeout == 0 {
+		env.timeout = time.Duration(cfg.Timeout)
+	}
+
+	return env, parts, name, nil
+}
+
+func (c Commands) runAllInputs(ctx context.Context, env runEnv, name string, year, day int, parts []int) error {
+	inputs, err := inputFiles(year, day)
+	if err != nil {
+		return fmt.Errorf("listing input files: %v", err)
+	}
+	if len(inputs) == 0 {
+		return fmt.Errorf("no input files exist for %s", filepath.Join(fmt.Sprint(year), fmt.Sprintf("day%d", day)))
+	}
+
+	if c.json {
+		docs := make([]puzzleDoc, 0, len(inputs)*len(parts))
+		for _, input := range inputs {
+			runs, err := env.runPuzzle(ctx, year, day, input, parts...)
+			if errors.Is(err, errTimeout) {
+				docs = append(docs, failedDocs(year, day, input, parts, outcomeTimeout)...)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			expected, err := readExpected(year, day, input)
+			if err != nil {
+				return err
+			}
+			if len(runs) == 0 {
+				docs = append(docs, failedDocs(year, day, input, parts, outcomeError)...)
+			}
+			for _, r := range runs {
+				docs = append(docs, runDoc(r.key, r.before, r.res, expected))
+			}
+		}
+		return printJSON(docs)
+	}
+
+	fmt.Printf("Running %s with all inputs\n", name)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, input := range inputs {
+		runs, err := env.runPuzzle(ctx, year, day, input, parts...)
+		if errors.Is(err, errTimeout) {
+			fmt.Fprintf(w, "%s\t\ttimeout\t\n", input)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		expected, err := readExpected(year, day, input)
+		if err != nil {
+			return err
+		}
+		if len(runs) == 0 {
+			fmt.Fprintf(w, "%s\t\terror\t\n", input)
+		}
+		for _, r := range runs {
+			label := input
+			if len(parts) > 1 {
+				label = fmt.Sprintf("%s\tpart%d", input, r.res.Part)
+			}
+			mark, res := "", r.res.Res
+			switch {
+			case r.before.locked && !r.before.correct(r.res):
+				mark, res = "x", fmt.Sprintf("%s (want %s)", res, r.before.res)
+			case r.before.locked:
+				mark = "*"
+			}
+			if want, ok := expected[r.res.Part]; ok {
+				if want == r.res.Res {
+					mark += "✓"
+				} else {
+					mark, res = mark+"✗", fmt.Sprintf("%s (want %s)", r.res.Res, want)
+				}
+			}
+			dur := fmt.Sprint(r.res.Dur)
+			if r.res.Bench != nil {
+				dur = fmt.Sprintf("%s\tmedian %v", dur, r.res.Bench.Median)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", label, mark, res, dur)
+		}
+	}
+
+	return w.Flush()
+}
+
+// runPuzzle runs the given parts of a puzzle with input and records the results in cache. A
+// solution that fails to compile or exits early leaves no results. A solution that doesn't finish
+// within the timeout of env, or is interrupted, is killed and leaves the cache untouched.
+func (env runEnv) runPuzzle(ctx context.Context, year, day int, input string, parts ...int) ([]puzzleRun, error) {
+	runnerKey := runnerCacheKey(year, day, input, parts)
+
+	for _, p := range parts {
+		if err := ensureRecord(cache.PuzzleKey{Year: year, Day: day, Part: p, Input: input}); err != nil {
+			return nil, fmt.Errorf("setting up record: %v", err)
+		}
+	}
+
+	src, err := genRunner(env.mod, runnerKey, year, day, input, parts...)
+	if err != nil {
+		return nil, fmt.Errorf("setting up runner: %v", err)
+	}
+
+	release, ok := acquire(ctx, env.builds)
+	if !ok {
+		return nil, errInterrupted
+	}
+	path, err := buildRunner(ctx, runnerKey, src, solutionPkg(env.mod, year, day), env.build)
+	release()
+	var buildErr errBuild
+	switch {
+	case ctx.Err() != nil:
+		return nil, errInterrupted
+	case errors.As(err, &buildErr):
+		if env.buildErrs != nil {
+			env.buildErrs(buildErr.output)
+		}
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("building runner: %v", err)
+	}
+
+	release, ok = acquire(ctx, env.runs)
+	if !ok {
+		return nil, errInterrupted
+	}
+	defer release()
+
+	runCtx := ctx
+	if env.timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, env.timeout)
+		defer cancel()
+	}
+
+	var usage exec.Usage
+	if env.out != nil {
+		usage, err = exec.BinaryAndPrint(runCtx, env.out, path, env.args...)
+	} else {
+		// Failing runners are otherwise recognized by their lack of results.
+		_, usage, err = exec.BinaryAndCapture(runCtx, path, env.args...)
+	}
+	switch {
+	case ctx.Err() != nil:
+		return nil, errInterrupted
+	case errors.Is(err, context.DeadlineExceeded):
+		return nil, errTimeout
+	case env.out != nil && err != nil:
+		return nil, fmt.Errorf("executing runner: %v", err)
+	}
+
+	results, err := readReport(runnerKey)
+	if err != nil {
+		return nil, err
+	}
+	// The peak memory of a runner can only be attributed to a part if it ran alone.
+	if len(results) == 1 {
+		results[0].RSS = usage.MaxRSS
+	}
+
+	entry := historyEntry{Time: time.Now(), Input: hashInput(inputPath(runnerKey, year, day, input))}
+	if env.vcs != nil {
+		vcs := env.vcs()
+		entry.Commit, entry.Dirty = vcs.commit, vcs.dirty
+	}
+
+	runs := make([]puzzleRun, 0, len(results))
+	for _, res := range results {
+		key := cache.PuzzleKey{Year: year, Day: day, Part: res.Part, Input: input}
+		rec, err := update(key, res)
+		if err != nil {
+			return nil, fmt.Errorf("updating cache: %v", err)
+		}
+		entry.Res, entry.Dur = res.Res, res.Dur
+		if err := appendHistory(key, entry); err != nil {
+			return nil, fmt.Errorf("updating history: %v", err)
+		}
+		runs = append(runs, puzzleRun{key: key, before: rec, res: res})
+	}
+
+	return runs, nil
+}
+
+// inputFiles lists, in alphabetical order, every .txt file in the input catalogue of a day.
+func inputFiles(year, day int) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(fmt.Sprint(year), "input", fmt.Sprintf("day%d", day), "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make([]string, 0, len(paths))
+	for _, p := range paths {
+		inputs = append(inputs, filepath.Base(p))
+	}
+	sort.Strings(inputs)
+
+	return inputs, nil
+}
+
+// runnerCacheKey returns the key under which the runner of the given parts of a puzzle is cached.
+// A runner of a single part shares the cache of the part.
+func runnerCacheKey(year, day int, input string, parts []int) cache.Key {
+	if len(parts) > 1 {
+		return cache.DayKey{Year: year, Day: day, Input: input}
+	}
+
+	return cache.PuzzleKey{Year: year, Day: day, Part: parts[0], Input: input}
+}
+
+// genRunner generates a runner for the given parts of a puzzle and stores it in cache under key.
+// The runner is regenerated every time so that it never lags behind the template or the module.
+func genRunner(mod string, key cache.Key, year, day int, input string, parts ...int) (string, error) {
+	dName := fmt.Sprintf("day%d", day)
+
+	type partData struct {
+		Num      int
+		FuncName string
+	}
+	pData := make([]partData, 0, len(parts))
+	for _, p := range parts {
+		pData = append(pData, partData{Num: p, FuncName: fmt.Sprintf("Part%d", p)})
+	}
 
 	fPaths, err := files.GenTemp(map[string]string{
 		files.Runner: runnerTmpl,
-		files.Lock:   strconv.FormatBool(false),
-		files.Res:    "",
-		files.Dur:    time.Duration(math.MaxInt64).String(),
-	}, map[string]string{
-		"PkgPath":   filepath.Join(mod, yName, "solutions", dName),
-		"PkgName":   dName,
-		"FuncName":  strings.Replace(pName, "p", "P", 1),
-		"InputPath": filepath.Join(yName, "input", dName, input),
-		"LockPath":  cache.MakePath(cacheKey, files.Lock),
-		"ResPath":   cache.MakePath(cacheKey, files.Res),
-		"DurPath":   cache.MakePath(cacheKey, files.Dur),
+	}, map[string]any{
+		"PkgPath":    solutionPkg(mod, year, day),
+		"PkgName":    dName,
+		"Parts":      pData,
+		"InputPath":  inputPath(key, year, day, input),
+		"ReportPath": cache.MakePath(key, files.Report),
 	})
 	if err != nil {
 		return "", fmt.Errorf("generating files: %v", err)
 	}
 
-	rPath := ""
-	for name, path := range fPaths {
-		cPath, err := cache.Store(cacheKey, name, path)
-		if err != nil {
-			return "", fmt.Errorf("caching files: %v", err)
-		}
-		if name == files.Runner {
-			rPath = cPath
-		}
+	rPath, err := cache.Store(key, files.Runner, fPaths[files.Runner])
+	if err != nil {
+		return "", fmt.Errorf("caching files: %v", err)
+	}
+
+	if err := cache.Remove(key, files.Report); err != nil {
+		return "", fmt.Errorf("removing old report: %v", err)
 	}
 
 	return rPath, nil
 }
 
+// solutionPkg returns the import path of the solution package of a day.
+func solutionPkg(mod string, year, day int) string {
+	return path.Join(mod, fmt.Sprint(year), "solutions", fmt.Sprintf("day%d", day))
+}
+
 func currentModulePath() (string, error) {
 	out, err := exec.CommandAndCapture("go", "env", "GOMOD")
 	if err != nil {
@@ -181,14 +790,14 @@ func currentModulePath() (string, error) {
 }
 
 func setLastRun(key cache.Key) error {
-	cPath, ok := cache.Contains(cache.ConfigKey{Domain: User}, files.LastRun)
+	cPath, ok := cache.Contains(cache.ProjectKey{Domain: User}, files.LastRun)
 	if !ok {
 		paths, err := files.GenTemp(map[string]string{files.LastRun: key.ID()}, nil)
 		if err != nil {
 			return fmt.Errorf("creating file: %v", err)
 		}
 
-		_, err = cache.Store(cache.ConfigKey{Domain: User}, files.LastRun, paths[files.LastRun])
+		_, err = cache.Store(cache.ProjectKey{Domain: User}, files.LastRun, paths[files.LastRun])
 		if err != nil {
 			return fmt.Errorf("caching last run puzzle: %v", err)
 		}
@@ -1,33 +1,149 @@
 package commands_test
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gombrii/aoc/internal/commands"
 	"github.com/otiai10/copy"
 )
 
 func TestRun(t *testing.T) {
-	testRoot, testCache, wd := prepare(t)
+	testRoot, _, wd := prepare(t)
 
 	initMod(t, wd, testRoot)
 	initDay(t, wd, testRoot)
 
-	if _, err := os.Stat(filepath.Join(testCache, "puzzles", "2024-day1-part1-input")); err == nil {
+	if _, err := os.Stat(puzzleDir(t, "2024-day1-part1-input")); err == nil {
 		t.Error("Cache already exists")
 	}
 
-	if err := (commands.Commands{}).Run(2024, 1, 1, "input.txt"); err != nil {
+	if err := (commands.Commands{}).Run(2024, 1, 1, "input.txt", commands.RunOpts{}); err != nil {
 		t.Errorf("calling Run: %v", err)
 	}
 
-	if _, err := os.Stat(filepath.Join(testCache, "puzzles", "2024-day1-part1-input")); err != nil {
+	if _, err := os.Stat(puzzleDir(t, "2024-day1-part1-input")); err != nil {
 		t.Error("Run wasn't cached")
 	}
 }
 
+func TestRunAllParts(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	if err := (commands.Commands{}).Run(2024, 1, 0, "input.txt", commands.RunOpts{}); err != nil {
+		t.Errorf("calling Run: %v", err)
+	}
+
+	for _, key := range []string{"2024-day1-part1-input", "2024-day1-part2-input"} {
+		data, err := os.ReadFile(filepath.Join(puzzleDir(t, key), "res"))
+		if err != nil {
+			t.Errorf("%s wasn't cached: %v", key, err)
+		} else if string(data) != "NOT IMPLEMENTED!" {
+			t.Errorf("%s cached result %q, want %q", key, data, "NOT IMPLEMENTED!")
+		}
+	}
+}
+
+func TestRunExpected(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	writeFile(t, "2024/input/day1/test.expected", "part1: NOT IMPLEMENTED!\npart2: 42\n")
+
+	var err error
+	out := captureStdout(t, func() { err = (commands.Commands{}).Run(2024, 1, 0, "test.txt", commands.RunOpts{}) })
+	if err != nil {
+		t.Fatalf("calling Run: %v", err)
+	}
+	for _, want := range []string{"Exp: ✓", "Exp: ✗ want 42"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("Run didn't print %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunAllInputsBuildError(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	writeFile(t, "2024/solutions/day1/part1.go", `package day1
+
+func Part1(data []byte) any {
+	return undefinedThing
+}
+`)
+
+	var err error
+	out := captureStderr(t, func() {
+		captureStdout(t, func() { err = (commands.Commands{}).Run(2024, 1, 1, "all", commands.RunOpts{}) })
+	})
+	if err != nil {
+		t.Fatalf("calling Run: %v", err)
+	}
+	if n := strings.Count(string(out), "undefinedThing"); n != 1 {
+		t.Errorf("compiler errors were printed %d times, want once:\n%s", n, out)
+	}
+}
+
+func TestRunAllPartsOwnInput(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	writeFile(t, "2024/input/day1/input.txt", "abc")
+	writeFile(t, "2024/solutions/day1/part1.go", `package day1
+
+func Part1(data []byte) any {
+	for i := range data {
+		data[i] = 'x'
+	}
+	return string(data)
+}
+`)
+	writeFile(t, "2024/solutions/day1/part2.go", `package day1
+
+func Part2(data []byte) any {
+	return string(data)
+}
+`)
+
+	if err := (commands.Commands{}).Run(2024, 1, 0, "input.txt", commands.RunOpts{}); err != nil {
+		t.Fatalf("calling Run: %v", err)
+	}
+	if res := cachedResult(t, "2024-day1-part2-input"); res != "abc" {
+		t.Errorf("part 2 got result %q, want %q of an input untouched by part 1", res, "abc")
+	}
+}
+
+func TestRunAllInputs(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	if err := (commands.Commands{}).Run(2024, 1, 1, "all", commands.RunOpts{}); err != nil {
+		t.Errorf("calling Run: %v", err)
+	}
+
+	for _, key := range []string{"2024-day1-part1-input", "2024-day1-part1-test"} {
+		if _, err := os.Stat(filepath.Join(puzzleDir(t, key), "res")); err != nil {
+			t.Errorf("%s wasn't cached", key)
+		}
+	}
+}
+
 func TestRunError(t *testing.T) {
 	for name, params := range map[string]struct {
 		puzzleFile string
@@ -43,7 +159,7 @@ func TestRunError(t *testing.T) {
 		},
 	} {
 		t.Run(name, func(t *testing.T) {
-			testRoot, testCache, wd := prepare(t)
+			testRoot, _, wd := prepare(t)
 
 			initMod(t, wd, testRoot)
 			initDay(t, wd, testRoot)
@@ -55,23 +171,390 @@ func TestRunError(t *testing.T) {
 				t.Fatalf("replacing part1.go in testDir: %v", err)
 			}
 
-			if err := (commands.Commands{}).Run(2024, 1, 1, "input.txt"); err != nil {
+			if err := (commands.Commands{}).Run(2024, 1, 1, "input.txt", commands.RunOpts{}); err != nil {
 				t.Errorf("calling Run: %v", err)
 			}
 
-			if _, err := os.Stat(filepath.Join(testCache, "puzzles", "2024-day1-part1-input")); err != nil {
+			if _, err := os.Stat(puzzleDir(t, "2024-day1-part1-input")); err != nil {
 				t.Error("Run wasn't cached")
 			}
 		})
 	}
 }
 
+func TestRunTimeout(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	srcPath := filepath.Join(wd, "testdata", "puzzlefiles", "loop.go")
+	dstPath := filepath.Join(testRoot, "2024", "solutions", "day1", "part1.go")
+
+	if err := copy.Copy(srcPath, dstPath); err != nil {
+		t.Fatalf("replacing part1.go in testDir: %v", err)
+	}
+
+	opts := commands.RunOpts{Timeout: 500 * time.Millisecond}
+	if err := (commands.Commands{}).Run(2024, 1, 1, "input.txt", opts); err == nil {
+		t.Error("Run of endless loop didn't return an error")
+	}
+
+	data, err := os.ReadFile(filepath.Join(puzzleDir(t, "2024-day1-part1-input"), "res"))
+	if err != nil {
+		t.Errorf("reading cached result: %v", err)
+	} else if len(data) != 0 {
+		t.Errorf("timed out run was cached with result %q", data)
+	}
+}
+
+func TestRunProfile(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	opts := commands.RunOpts{CPUProfile: "cpu.out", MemProfile: "mem.out", Trace: "trace.out"}
+	if err := (commands.Commands{}).Run(2024, 1, 1, "input.txt", opts); err != nil {
+		t.Errorf("calling Run: %v", err)
+	}
+
+	for _, path := range []string{
+		filepath.Join(testRoot, "cpu.out"),
+		filepath.Join(testRoot, "mem.out"),
+		filepath.Join(testRoot, "trace.out"),
+		filepath.Join(puzzleDir(t, "2024-day1-part1-input"), "cpu.pprof"),
+		filepath.Join(puzzleDir(t, "2024-day1-part1-input"), "mem.pprof"),
+		filepath.Join(puzzleDir(t, "2024-day1-part1-input"), "trace.out"),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("profile wasn't written: %v", err)
+		}
+	}
+
+	if err := (commands.Commands{}).Run(2024, 1, 0, "input.txt", opts); err == nil {
+		t.Error("Run profiling both parts didn't return an error")
+	}
+}
+
+func TestRunBuildFlags(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	if err := (commands.Commands{}).Run(2024, 1, 1, "input.txt", commands.RunOpts{}); err != nil {
+		t.Errorf("calling Run: %v", err)
+	}
+	opts := commands.RunOpts{Build: commands.BuildOpts{Tags: "foo"}}
+	if err := (commands.Commands{}).Run(2024, 1, 1, "input.txt", opts); err != nil {
+		t.Errorf("calling Run: %v", err)
+	}
+
+	binaries, err := filepath.Glob(filepath.Join(puzzleDir(t, "2024-day1-part1-input"), "runner*"))
+	if err != nil {
+		t.Fatalf("listing binaries: %v", err)
+	}
+	// runner.go, the plain build and the build with tags.
+	if len(binaries) != 3 {
+		t.Errorf("got %d runner files, want 3: %v", len(binaries), binaries)
+	}
+}
+
+func TestRunReusesBinary(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	bin := filepath.Join(puzzleDir(t, "2024-day1-part1-input"), "runner")
+	var built time.Time
+	for i := range 2 {
+		if err := (commands.Commands{}).Run(2024, 1, 1, "input.txt", commands.RunOpts{}); err != nil {
+			t.Fatalf("calling Run: %v", err)
+		}
+		info, err := os.Stat(bin)
+		if err != nil {
+			t.Fatalf("binary wasn't cached: %v", err)
+		}
+		if i > 0 && !info.ModTime().Equal(built) {
+			t.Error("binary was rebuilt without any change")
+		}
+		built = info.ModTime()
+	}
+}
+
+func TestRunDependencyChange(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	writeFile(t, "2024/solutions/day1/part1.go", `package day1
+
+import "senap/shared/extra"
+
+func Part1(data []byte) any {
+	return extra.V()
+}
+`)
+	for _, v := range []string{"1", "2"} {
+		writeFile(t, "shared/extra/extra.go", "package extra\n\nfunc V() int { return "+v+" }\n")
+		if err := (commands.Commands{}).Run(2024, 1, 1, "input.txt", commands.RunOpts{}); err != nil {
+			t.Fatalf("calling Run: %v", err)
+		}
+		if res := cachedResult(t, "2024-day1-part1-input"); res != v {
+			t.Errorf("got result %q, want %q of the changed dependency", res, v)
+		}
+	}
+}
+
+func TestRunTagDependencyChange(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	writeFile(t, "2024/solutions/day1/part1.go", `package day1
+
+var v = func() int { return 0 }
+
+func Part1(data []byte) any {
+	return v()
+}
+`)
+	writeFile(t, "2024/solutions/day1/foo.go", `//go:build foo
+
+package day1
+
+import "senap/shared/extra"
+
+func init() { v = extra.V }
+`)
+	opts := commands.RunOpts{Build: commands.BuildOpts{Tags: "foo"}}
+	for _, v := range []string{"1", "2"} {
+		writeFile(t, "shared/extra/extra.go", "package extra\n\nfunc V() int { return "+v+" }\n")
+		if err := (commands.Commands{}).Run(2024, 1, 1, "input.txt", opts); err != nil {
+			t.Fatalf("calling Run: %v", err)
+		}
+		if res := cachedResult(t, "2024-day1-part1-input"); res != v {
+			t.Errorf("got result %q, want %q of the changed dependency", res, v)
+		}
+	}
+}
+
+func TestRunEmbedChange(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	writeFile(t, "2024/solutions/day1/part1.go", `package day1
+
+import _ "embed"
+
+//go:embed note.txt
+var note string
+
+func Part1(data []byte) any {
+	return note
+}
+`)
+	for _, note := range []string{"before", "after"} {
+		writeFile(t, "2024/solutions/day1/note.txt", note)
+		if err := (commands.Commands{}).Run(2024, 1, 1, "input.txt", commands.RunOpts{}); err != nil {
+			t.Fatalf("calling Run: %v", err)
+		}
+		if res := cachedResult(t, "2024-day1-part1-input"); res != note {
+			t.Errorf("got result %q, want %q of the embedded file", res, note)
+		}
+	}
+}
+
+func TestRunBench(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	cmd := commands.Commands{}
+	opts := commands.RunOpts{Bench: true, BenchTime: "10x"}
+	if err := cmd.Run(2024, 1, 1, "input.txt", opts); err != nil {
+		t.Fatalf("calling Run: %v", err)
+	}
+	benchFile := filepath.Join(puzzleDir(t, "2024-day1-part1-input"), "bench")
+	data, err := os.ReadFile(benchFile)
+	if err != nil {
+		t.Fatalf("bench wasn't cached: %v", err)
+	}
+	if median, err := time.ParseDuration(string(data)); err != nil || median <= 0 {
+		t.Errorf("cached median %q isn't a duration", data)
+	}
+
+	// A locked puzzle only remembers its best median.
+	if err := cmd.Lock(2024, 1, 1, "input.txt"); err != nil {
+		t.Fatalf("calling Lock: %v", err)
+	}
+	for _, step := range []struct {
+		best   string
+		better bool
+	}{
+		{best: "1ns", better: false},
+		{best: "1h", better: true},
+	} {
+		writeFile(t, benchFile, step.best)
+		if err := cmd.Run(2024, 1, 1, "input.txt", opts); err != nil {
+			t.Fatalf("calling Run: %v", err)
+		}
+		data, err := os.ReadFile(benchFile)
+		if err != nil {
+			t.Fatalf("reading bench: %v", err)
+		}
+		if replaced := string(data) != step.best; replaced != step.better {
+			t.Errorf("best median %s became %s, want it replaced only if beaten", step.best, data)
+		}
+	}
+}
+
+func TestRunBuildFlagsOverrideConfig(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	writeFile(t, "aoc.json", `{"build": {"tags": "broken"}}`)
+	writeFile(t, "2024/solutions/day1/broken.go", "//go:build broken\n\npackage day1\n\nvar _ = undefinedThing\n")
+
+	for _, step := range []struct {
+		build  commands.BuildOpts
+		builds bool
+	}{
+		{build: commands.BuildOpts{}, builds: false},
+		{build: commands.BuildOpts{Provided: []string{"tags"}}, builds: true},
+	} {
+		var err error
+		out := captureStderr(t, func() {
+			err = (commands.Commands{}).Run(2024, 1, 1, "input.txt", commands.RunOpts{Build: step.build})
+		})
+		if err != nil {
+			t.Fatalf("calling Run: %v", err)
+		}
+		if built := !strings.Contains(string(out), "undefinedThing"); built != step.builds {
+			t.Errorf("with %+v the puzzle was built: %t, want %t", step.build, built, step.builds)
+		}
+	}
+}
+
+func TestRunPath(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	path := filepath.Join(t.TempDir(), "stress.txt")
+	if err := os.WriteFile(path, []byte("1 2 3"), 0644); err != nil {
+		t.Fatalf("writing input: %v", err)
+	}
+
+	if err := (commands.Commands{}).Run(2024, 1, 1, path, commands.RunOpts{}); err != nil {
+		t.Errorf("calling Run: %v", err)
+	}
+
+	keys, err := filepath.Glob(filepath.Join(filepath.Dir(puzzleDir(t, "2024-day1-part1-input")), "2024-day1-part1-@*"))
+	if err != nil {
+		t.Fatalf("listing cache: %v", err)
+	}
+	if len(keys) != 1 {
+		t.Fatalf("got %d ad-hoc records, want 1: %v", len(keys), keys)
+	}
+	if _, err := os.Stat(filepath.Join(keys[0], "res")); err != nil {
+		t.Error("Run wasn't cached")
+	}
+
+	if err := (commands.Commands{}).Run(2024, 1, 1, filepath.Join(testRoot, "missing.txt"), commands.RunOpts{}); err == nil {
+		t.Error("Run with missing input file didn't return an error")
+	}
+}
+
+func TestRunProjectCache(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	if err := (commands.Commands{}).Run(2024, 1, 1, "input.txt", commands.RunOpts{}); err != nil {
+		t.Fatalf("calling Run: %v", err)
+	}
+	first := puzzleDir(t, "2024-day1-part1-input")
+
+	// A clone of the same module elsewhere has a cache of its own.
+	clone := t.TempDir()
+	initMod(t, wd, clone)
+	initDay(t, wd, clone)
+	t.Chdir(clone)
+
+	if second := puzzleDir(t, "2024-day1-part1-input"); second == first {
+		t.Fatalf("clones share cache %s", first)
+	}
+
+	if err := os.Mkdir(filepath.Join(clone, ".aoc"), 0755); err != nil {
+		t.Fatalf("creating local cache: %v", err)
+	}
+	if err := (commands.Commands{}).Run(2024, 1, 1, "input.txt", commands.RunOpts{}); err != nil {
+		t.Fatalf("calling Run: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(clone, ".aoc", "puzzles", "2024-day1-part1-input", "res")); err != nil {
+		t.Error("Run wasn't cached in the module")
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	cmd := commands.Commands{}
+	cmd.SetJSON(true)
+
+	var err error
+	out := captureStdout(t, func() {
+		err = cmd.Run(2024, 1, 0, "input.txt", commands.RunOpts{})
+	})
+	if err != nil {
+		t.Errorf("calling Run: %v", err)
+	}
+
+	var docs []struct {
+		Year    int
+		Day     int
+		Part    int
+		Input   string
+		Outcome string
+		Result  string
+	}
+	if err := json.Unmarshal(out, &docs); err != nil {
+		t.Fatalf("output isn't JSON: %v\n%s", err, out)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("got %d documents, want 2", len(docs))
+	}
+	for i, doc := range docs {
+		if doc.Year != 2024 || doc.Day != 1 || doc.Part != i+1 || doc.Input != "input.txt" {
+			t.Errorf("document describes the wrong puzzle: %+v", doc)
+		}
+		if doc.Outcome != "unlocked" || doc.Result != "NOT IMPLEMENTED!" {
+			t.Errorf("got outcome %q and result %q, want %q and %q", doc.Outcome, doc.Result, "unlocked", "NOT IMPLEMENTED!")
+		}
+	}
+}
+
 func TestRunNoMod(t *testing.T) {
 	testRoot, testCache, wd := prepare(t)
 
 	initDay(t, wd, testRoot)
 
-	if err := (commands.Commands{}).Run(2024, 1, 1, "input.txt"); err == nil {
+	if err := (commands.Commands{}).Run(2024, 1, 1, "input.txt", commands.RunOpts{}); err == nil {
 		t.Error("Calling Run outside module did not return an error")
 	}
 
@@ -85,7 +568,7 @@ func TestRunNoDay(t *testing.T) {
 
 	initMod(t, wd, testRoot)
 
-	if err := (commands.Commands{}).Run(2024, 1, 1, "input.txt"); err == nil {
+	if err := (commands.Commands{}).Run(2024, 1, 1, "input.txt", commands.RunOpts{}); err == nil {
 		t.Error("Calling Run without day target did not return an error")
 	}
 
@@ -97,7 +580,7 @@ func TestRunNoDay(t *testing.T) {
 func TestRunNeitherModNorDay(t *testing.T) {
 	_, testCache, _ := prepare(t)
 
-	if err := (commands.Commands{}).Run(2024, 1, 1, "input.txt"); err == nil {
+	if err := (commands.Commands{}).Run(2024, 1, 1, "input.txt", commands.RunOpts{}); err == nil {
 		t.Error("Calling Run outside mod and without day target did not return an error")
 	}
 
@@ -105,3 +588,18 @@ func TestRunNeitherModNorDay(t *testing.T) {
 		t.Error("Cache was created despite Run returning error")
 	}
 }
+
+func TestRunSubmitNotInput(t *testing.T) {
+	testRoot, _, wd := prepare(t)
+
+	initMod(t, wd, testRoot)
+	initDay(t, wd, testRoot)
+
+	cmd := commands.Commands{}
+	if err := cmd.Run(2024, 1, 1, "test.txt", commands.RunOpts{Submit: true}); err == nil {
+		t.Error("Calling Run to submit the result of test.txt did not return an error")
+	}
+	if err := cmd.Run(2024, 1, 0, "input.txt", commands.RunOpts{Submit: true}); err == nil {
+		t.Error("Calling Run to submit the results of both parts did not return an error")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"strings"
 
@@ -12,92 +13,217 @@ import (
 	"github.com/gombrii/aoc/internal/files"
 )
 
-func (c Commands) Submit() error {
+// SubmitOpts holds the optional ways of submitting an answer.
+type SubmitOpts struct {
+	// Year, Day and Part pick the puzzle to submit an answer for, run with input.txt. Zero Day
+	// picks the puzzle run last.
+	Year int
+	Day  int
+	Part int
+	// Answer is submitted instead of the result of the puzzle, if given.
+	Answer string
+	// Yes submits without asking for confirmation first.
+	Yes bool
+}
+
+func (c Commands) Submit(opts SubmitOpts) error {
 	session, ok := LoggedIn()
 	if !ok {
 		return errors.New("no logged in user")
 	}
 
-	res, puzzleKey, err := fetchLastResult()
-	if err != nil {
-		return err
+	puzzleKey := cache.PuzzleKey{Year: opts.Year, Day: opts.Day, Part: opts.Part, Input: "input.txt"}
+	if opts.Day == 0 {
+		key, err := lastRun()
+		if err != nil {
+			return err
+		}
+		puzzleKey = key
+	}
+	res := strings.TrimSpace(opts.Answer)
+	if res == "" {
+		var err error
+		if res, err = storedResult(puzzleKey); err != nil {
+			return err
+		}
 	}
 
-	fmt.Printf("Submit answer %q for %d/day%d/part%d? [y/N]: ", res, puzzleKey.Year, puzzleKey.Day, puzzleKey.Part)
-	reader := bufio.NewReader(os.Stdin)
-	s, err := reader.ReadString('\n')
+	return c.submit(session, puzzleKey, res, opts.Yes)
+}
+
+// submit submits res as the answer of the puzzle of key, unless it's known to be wrong, and locks
+// the puzzle if it's right. The user is asked for confirmation first, unless yes.
+func (c Commands) submit(session string, puzzleKey cache.PuzzleKey, res string, yes bool) error {
+	guesses, err := readGuesses(puzzleKey)
 	if err != nil {
-		return fmt.Errorf("reading user submit input: %v", err)
+		return fmt.Errorf("reading guesses: %v", err)
+	}
+	if why, ok := ruledOut(res, guesses); ok {
+		return fmt.Errorf("not submitting answer: %s", why)
 	}
 
-	s = strings.TrimSpace(s)
-	s = strings.ToLower(s)
-	if s != "y" && s != "yes" {
-		return nil
+	// The prompt mustn't end up in JSON output.
+	prompt := os.Stdout
+	if c.json {
+		prompt = os.Stderr
 	}
+	doc := submitDoc{Year: puzzleKey.Year, Day: puzzleKey.Day, Part: puzzleKey.Part, Answer: res}
+
+	if !yes {
+		fmt.Fprintf(prompt, "Submit answer %q for %d/day%d/part%d? [y/N]: ", res, puzzleKey.Year, puzzleKey.Day, puzzleKey.Part)
+		reader := bufio.NewReader(os.Stdin)
+		s, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("reading user submit input: %v", err)
+		}
 
-	fmt.Println() // Add spacer
-	if err := com.Submit(com.NewClient(session), puzzleKey.Year, puzzleKey.Day, puzzleKey.Part, res); err != nil {
-		switch {
-		case errors.Is(err, com.ErrAnswerHigh):
-			fmt.Println("Incorrect! Answer is too high.")
+		s = strings.TrimSpace(s)
+		s = strings.ToLower(s)
+		if s != "y" && s != "yes" {
+			if c.json {
+				doc.Outcome = submitCancelled
+				return printJSON(doc)
+			}
 			return nil
-		case errors.Is(err, com.ErrAnswerHigh):
-			fmt.Println("Incorrect! Answer is too low.")
-			return nil
-		case errors.Is(err, com.ErrAlreadySolved):
-			fmt.Println("This puzzle has already been solved. Go ahead and continue your quest. :)")
-			return nil
-		default:
-			return fmt.Errorf("submitting answer to server: %v", err)
+		}
+
+		if !c.json {
+			fmt.Println() // Add spacer
 		}
 	}
 
-	lPath, ok := cache.Contains(puzzleKey, files.Lock)
-	if !ok {
-		return fmt.Errorf("checking lock for %s: %v", puzzleKey.ID(), err)
+	// Guesses are kept along with the record of the puzzle, which an explicit answer may have none of.
+	if err := ensureRecord(puzzleKey); err != nil {
+		return fmt.Errorf("creating record of %s: %v", puzzleKey.ID(), err)
+	}
+	if err := com.Submit(com.NewClient(session), puzzleKey.Year, puzzleKey.Day, puzzleKey.Part, res); err != nil {
+		return c.rejected(puzzleKey, doc, err)
+	}
+	if err := recordGuess(puzzleKey, res, outcomeCorrect); err != nil {
+		return fmt.Errorf("recording guess: %v", err)
+	}
+	if err := lockSubmitted(puzzleKey, res); err != nil {
+		return err
 	}
 
-	err = files.Write(lPath, []byte("true"))
-	if err != nil {
-		return fmt.Errorf("setting lock to true: %v", err)
+	if c.json {
+		doc.Outcome = outcomeCorrect
+		return printJSON(doc)
 	}
 
-	fmt.Println("Correct answer! \033[38;2;255;255;103m*\033[0m")
+	fmt.Println("Correct answer!", paint(yellow, "*"))
 	fmt.Println("This answer is now locked in. Future runs will error if they produce a different result.")
 	fmt.Println("To verify all locked puzzle results, run 'aoc check'.")
 
 	return nil
 }
 
-func fetchLastResult() (string, cache.PuzzleKey, error) {
-	path, ok := cache.Contains(cache.ConfigKey{Domain: User}, files.LastRun)
+// rejected reports an answer the server didn't accept, telling what to do about it when it can, and
+// records it among the guesses of the puzzle of key.
+func (c Commands) rejected(key cache.PuzzleKey, doc submitDoc, err error) error {
+	var msg string
+	var wrong com.WrongAnswerError
+	var tooRecent com.TooRecentError
+	switch {
+	case errors.As(err, &wrong):
+		doc.Wait = int64(wrong.Wait)
+		switch {
+		case errors.Is(err, com.ErrAnswerHigh):
+			doc.Outcome, msg = submitTooHigh, "Incorrect! Answer is too high."
+		case errors.Is(err, com.ErrAnswerLow):
+			doc.Outcome, msg = submitTooLow, "Incorrect! Answer is too low."
+		default:
+			doc.Outcome, msg = submitWrong, "Incorrect! The server gave no hint whether the answer is too high or too low."
+		}
+		if wrong.Wait > 0 {
+			msg += fmt.Sprintf("\nWait %v before submitting another answer.", wrong.Wait)
+		}
+	case errors.As(err, &tooRecent):
+		doc.Wait = int64(tooRecent.Wait)
+		doc.Outcome = submitTooRecent
+		msg = fmt.Sprintf("An answer was submitted too recently. Wait %v before trying again, the answer wasn't checked.", tooRecent.Wait)
+	case errors.Is(err, com.ErrWrongLevel):
+		doc.Outcome = submitWrongLevel
+		msg = fmt.Sprintf("This isn't the part you're on. Either %d/day%d/part%d is already solved, or part 1 isn't solved yet.", doc.Year, doc.Day, doc.Part)
+	case errors.Is(err, com.ErrNotUnlocked):
+		doc.Outcome = submitNotUnlocked
+		msg = fmt.Sprintf("%d/day%d isn't unlocked yet. Puzzles unlock at midnight EST (UTC-5).", doc.Year, doc.Day)
+	default:
+		return fmt.Errorf("submitting answer to server: %v", err)
+	}
+
+	if err := recordGuess(key, doc.Answer, doc.Outcome); err != nil {
+		return fmt.Errorf("recording guess: %v", err)
+	}
+
+	if c.json {
+		return printJSON(doc)
+	}
+	fmt.Println(msg)
+
+	return nil
+}
+
+// lockSubmitted locks the puzzle of key to res, an answer the server accepted. The best duration is
+// only kept if it was measured with that very result.
+func lockSubmitted(key cache.PuzzleKey, res string) error {
+	rec, err := readRecord(key)
+	if err != nil {
+		return fmt.Errorf("reading record of %s: %v", key.ID(), err)
+	}
+	if rec.res != res {
+		rec.res, rec.dur = res, math.MaxInt64
+		err := files.Write(cache.MakePath(key, files.Res), []byte(res))
+		if err == nil {
+			err = files.Write(cache.MakePath(key, files.Dur), []byte(rec.dur.String()))
+		}
+		if err != nil {
+			return fmt.Errorf("recording answer: %v", err)
+		}
+	}
+
+	return lock(key, rec)
+}
+
+// lastRun returns the key of the puzzle run last, if it can be submitted.
+func lastRun() (cache.PuzzleKey, error) {
+	path, ok := cache.Contains(cache.ProjectKey{Domain: User}, files.LastRun)
 	if !ok {
-		return "", cache.PuzzleKey{}, errors.New("no puzzle has yet been run")
+		return cache.PuzzleKey{}, errors.New("no puzzle has yet been run")
 	}
 
 	data, err := files.Read(path)
 	if err != nil {
-		return "", cache.PuzzleKey{}, fmt.Errorf("checking last run: %v", err)
+		return cache.PuzzleKey{}, fmt.Errorf("checking last run: %v", err)
 	}
 
 	key, err := cache.ParsePuzzleKey(string(data))
 	if err != nil {
-		return "", cache.PuzzleKey{}, fmt.Errorf("parsing cache key: %v", err)
+		return cache.PuzzleKey{}, fmt.Errorf("parsing cache key: %v", err)
+	}
+	if adHoc(key.Input) {
+		return cache.PuzzleKey{}, errors.New("last run was with stdin or a file outside the input catalogue, which can't be submitted")
 	}
 	if !strings.HasSuffix(key.ID(), "-input") {
-		return "", cache.PuzzleKey{}, errors.New("last run was not with input file input.txt")
+		return cache.PuzzleKey{}, errors.New("last run was not with input file input.txt")
 	}
 
-	path, ok = cache.Contains(key, files.Res)
-	if !ok {
-		return "", cache.PuzzleKey{}, errors.New("no result for run")
+	return key, nil
+}
+
+// storedResult returns the result recorded for the puzzle of key.
+func storedResult(key cache.PuzzleKey) (string, error) {
+	if !hasRecord(key) {
+		return "", fmt.Errorf("no result of %d/day%d/part%d to submit, run it first or give the answer", key.Year, key.Day, key.Part)
 	}
 
-	data, err = files.Read(path)
+	rec, err := readRecord(key)
 	if err != nil {
-		return "", cache.PuzzleKey{}, fmt.Errorf("checking result of last run: %v", err)
+		return "", fmt.Errorf("reading record of %s: %v", key.ID(), err)
+	}
+	if rec.res == "" {
+		return "", fmt.Errorf("no result of %d/day%d/part%d to submit, run it first or give the answer", key.Year, key.Day, key.Part)
 	}
 
-	return string(data), key, nil
+	return rec.res, nil
 }
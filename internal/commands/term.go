@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+)
+
+// Colours of text, as red;green;blue.
+const (
+	red    = "255;0;0"
+	yellow = "255;255;103"
+)
+
+// isTerminal tells whether f is a terminal, rather than a pipe or a file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// paint colours s for printing to stdout, unless no colour is given, stdout isn't a terminal or the
+// user has opted out of colours with NO_COLOR (https://no-color.org).
+func paint(colour, s string) string {
+	if colour == "" || os.Getenv("NO_COLOR") != "" || !isTerminal(os.Stdout) {
+		return s
+	}
+
+	return fmt.Sprintf("\033[38;2;%sm%s\033[0m", colour, s)
+}
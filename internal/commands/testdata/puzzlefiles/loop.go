@@ -0,0 +1,7 @@
+// Package day1 solves puzzle available on https://adventofcode.com/2024/day/1
+package day1
+
+func Part1(data []byte) any {
+	for {
+	}
+}
@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gombrii/aoc/internal/files"
+	"github.com/gombrii/aoc/internal/watch"
+)
+
+const (
+	pollInterval = 100 * time.Millisecond
+	// settleTime is how long files have to stay untouched after a change before the puzzle is run,
+	// so that a save touching several files only triggers one run.
+	settleTime = 200 * time.Millisecond
+)
+
+// Watch runs a puzzle like Run, and runs it again every time its solution, the shared packages of
+// the module or its input changes, until interrupted. A run still in progress when a change arrives
+// is killed in favour of a new one.
+func (c Commands) Watch(year, day, part int, input string, opts RunOpts) error {
+	if input == allInputs || input == stdinInput {
+		return fmt.Errorf("can't watch a puzzle with input %q", input)
+	}
+
+	env, parts, name, err := prepareRun(year, day, part, opts)
+	if err != nil {
+		return err
+	}
+	env.out = os.Stdout
+
+	yName := fmt.Sprintf("%d", year)
+	dName := fmt.Sprintf("day%d", day)
+
+	label, inFile := input, input
+	if isFileName(input) {
+		inFile = filepath.Join(yName, "input", dName, input)
+	}
+	if !files.Exists(inFile) {
+		return fmt.Errorf("input file %s does not exist", inFile)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	changes := watch.Changes(ctx, pollInterval, settleTime,
+		filepath.Join(yName, "solutions", dName), "shared", "go.mod", inFile)
+
+	w := watcher{env: env, year: year, day: day, parts: parts, name: name, label: label, prev: make(map[int]result)}
+	for {
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			w.run(runCtx, input)
+		}()
+
+		// Changes is closed once interrupted, which mustn't be mistaken for a change that starts
+		// another run, and neither must a change that arrives along with the interrupt.
+		select {
+		case <-ctx.Done():
+		case _, ok := <-changes:
+			if ok && ctx.Err() == nil {
+				cancel()
+				<-done
+				continue
+			}
+		}
+		cancel()
+		<-done
+		fmt.Println()
+		return nil
+	}
+}
+
+// watcher remembers the outcome of the previous run of a watched puzzle to compare a new one with.
+type watcher struct {
+	env   runEnv
+	year  int
+	day   int
+	parts []int
+	name  string
+	label string
+	prev  map[int]result // by part
+}
+
+func (w *watcher) run(ctx context.Context, input string) {
+	fmt.Print("\033[H\033[2J")
+	fmt.Printf("Watching %s with %s (%s), Ctrl-C to stop\n", w.name, w.label, time.Now().Format(time.TimeOnly))
+
+	// Path inputs are staged anew for every run, as the file itself is watched.
+	if !isFileName(input) {
+		var err error
+		if input, err = stageInput(w.year, w.day, input, w.parts); err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+	}
+
+	// The module changes from run to run.
+	w.env.vcs = sync.OnceValue(currentVCS)
+	w.env.buildErrs = reportBuild()
+	runs, err := w.env.runPuzzle(ctx, w.year, w.day, input, w.parts...)
+	switch {
+	case errors.Is(err, errInterrupted):
+		return
+	case errors.Is(err, errTimeout):
+		fmt.Printf("Error: timed out after %v\n", w.env.timeout)
+		return
+	case err != nil:
+		fmt.Println("Error:", err)
+		return
+	}
+
+	expected, err := readExpected(w.year, w.day, input)
+	if err != nil {
+		fmt.Println("Error:", err)
+	}
+
+	for _, r := range runs {
+		if len(w.parts) > 1 {
+			fmt.Printf("Part %d\n", r.res.Part)
+		}
+		prev, ok := w.prev[r.res.Part]
+		printWatched(r.before, r.res, prev, ok)
+		printExpected(expected, r.res)
+		setLastRun(r.key)
+		w.prev[r.res.Part] = r.res
+	}
+}
+
+// printWatched prints the result of a run compared to the previous run, and its duration compared
+// to the best of a locked puzzle or the previous run of an unlocked one.
+func printWatched(rec record, res, prev result, hasPrev bool) {
+	if rec.locked && !rec.correct(res) {
+		fmt.Printf("Error: res: %v, want %v\n", res.Res, rec.res)
+		return
+	}
+
+	switch {
+	case !hasPrev:
+		fmt.Println("Res:", res.Res)
+	case prev.Res != res.Res:
+		fmt.Printf("Res: %s (was %s)\n", res.Res, prev.Res)
+	default:
+		fmt.Printf("Res: %s (unchanged)\n", res.Res)
+	}
+
+	switch {
+	case rec.locked:
+		diff := res.Dur - rec.dur
+		fmt.Printf("Dur: %v (best %v, %.0f%%)\n", res.Dur, rec.dur, (float64(diff)/float64(res.Dur))*100.0)
+	case hasPrev:
+		diff := res.Dur - prev.Dur
+		fmt.Printf("Dur: %v (was %v, %.0f%%)\n", res.Dur, prev.Dur, (float64(diff)/float64(res.Dur))*100.0)
+	default:
+		fmt.Println("Dur:", res.Dur)
+	}
+}
@@ -0,0 +1,139 @@
+// Package config reads the optional project configuration of aoc, kept in the file aoc.json in the
+// root of the module. Every setting is optional.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const File = "aoc.json"
+
+type Config struct {
+	// Timeout is the default time a puzzle may run before it's killed. Zero means no timeout.
+	Timeout Duration `json:"timeout,omitempty"`
+	// Build holds the default options of the go toolchain when building puzzles.
+	Build Build `json:"build,omitempty"`
+	// Slow is how much slower than its best a locked puzzle may get before check fails it. Zero
+	// means never.
+	Slow Threshold `json:"slow,omitempty"`
+}
+
+// Build mirrors the flags of go build by the same names.
+type Build struct {
+	Race    bool   `json:"race,omitempty"`
+	Tags    string `json:"tags,omitempty"`
+	GCFlags string `json:"gcflags,omitempty"`
+	PGO     string `json:"pgo,omitempty"`
+}
+
+// Duration is a time.Duration written as a string in the config file, eg. "30s".
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+
+	return nil
+}
+
+// Threshold is a margin of a duration, either relative, eg. "20%", or absolute, eg. "5ms".
+type Threshold struct {
+	Percent float64
+	Abs     time.Duration
+}
+
+func (t Threshold) IsZero() bool {
+	return t.Percent == 0 && t.Abs == 0
+}
+
+// Exceeded tells whether dur is beyond the margin of best. A zero threshold is never exceeded.
+func (t Threshold) Exceeded(best, dur time.Duration) bool {
+	switch {
+	case t.Percent != 0:
+		return float64(dur) > float64(best)*(1+t.Percent/100)
+	case t.Abs != 0:
+		return dur > best+t.Abs
+	default:
+		return false
+	}
+}
+
+func (t Threshold) String() string {
+	switch {
+	case t.Percent != 0:
+		return strconv.FormatFloat(t.Percent, 'f', -1, 64) + "%"
+	case t.Abs != 0:
+		return t.Abs.String()
+	default:
+		return ""
+	}
+}
+
+// Set parses a threshold, which makes it a flag.Value.
+func (t *Threshold) Set(s string) error {
+	if pct, ok := strings.CutSuffix(s, "%"); ok {
+		f, err := strconv.ParseFloat(pct, 64)
+		if err != nil || f <= 0 {
+			return fmt.Errorf("invalid percentage %q", s)
+		}
+		*t = Threshold{Percent: f}
+		return nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return fmt.Errorf("invalid threshold %q, want a percentage or a duration", s)
+	}
+	*t = Threshold{Abs: d}
+
+	return nil
+}
+
+func (t Threshold) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+func (t *Threshold) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	return t.Set(s)
+}
+
+// Load reads the config file in the current directory. A missing file is an empty config.
+func Load() (Config, error) {
+	data, err := os.ReadFile(File)
+	if errors.Is(err, os.ErrNotExist) {
+		return Config{}, nil
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("reading %s: %v", File, err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("parsing %s: %v", File, err)
+	}
+
+	return cfg, nil
+}
@@ -1,28 +1,84 @@
 package exec
 
 import (
+	"context"
 	"errors"
+	"io"
 	"os"
 	"os/exec"
+	"time"
 )
 
-func BinaryAndPrint(path string) error {
-	cmd := exec.Command("go", "run", path)
-	cmd.Stdout = os.Stdout
+// waitDelay is how long to wait for the output of a killed process before giving up on it.
+const waitDelay = time.Second
+
+// Build compiles the Go source file src into the binary dst, with any extra flags of go build, and
+// returns the output of the compiler.
+func Build(ctx context.Context, src, dst string, flags ...string) ([]byte, error) {
+	args := append([]string{"build"}, flags...)
+	args = append(args, "-o", dst, src)
+
+	return exec.CommandContext(ctx, "go", args...).CombinedOutput()
+}
+
+// Usage is what the OS tells about the resources used by a process.
+type Usage struct {
+	MaxRSS uint64 // peak resident set size in bytes, zero if unknown
+}
+
+// BinaryAndPrint runs the binary at path until it exits or ctx is done, whichever comes first,
+// writing its output to stdout. In the latter case the binary is killed along with any process it
+// started and ctx.Err() is returned.
+func BinaryAndPrint(ctx context.Context, stdout io.Writer, path string, args ...string) (Usage, error) {
+	cmd := binary(ctx, path, args...)
+	cmd.Stdout = stdout
 	cmd.Stderr = os.Stderr
 
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return Usage{}, ctx.Err()
+	}
+
 	var exitErr *exec.ExitError
-	if err := cmd.Run(); err != nil && !errors.As(err, &exitErr) {
-		return err
+	if err != nil && !errors.As(err, &exitErr) {
+		return Usage{}, err
+	}
+
+	return usage(cmd.ProcessState), nil
+}
+
+// BinaryAndCapture runs the binary at path, like BinaryAndPrint, and returns its output.
+func BinaryAndCapture(ctx context.Context, path string, args ...string) ([]byte, Usage, error) {
+	cmd := binary(ctx, path, args...)
+	out, err := cmd.Output()
+	if ctx.Err() != nil {
+		return out, Usage{}, ctx.Err()
 	}
 
-	return nil
+	return out, usage(cmd.ProcessState), err
 }
 
-func BinaryAndCapture(path string) ([]byte, error) {
-	return exec.Command("go", "run", path).Output()
+// CommandInteractive runs a command connected to the terminal, for tools that talk to the user.
+func CommandInteractive(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
 }
 
 func CommandAndCapture(name string, args ...string) ([]byte, error) {
 	return exec.Command(name, args...).Output()
 }
+
+func binary(ctx context.Context, path string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, path, args...)
+	isolate(cmd)
+	cmd.Cancel = func() error {
+		return killGroup(cmd.Process)
+	}
+	cmd.WaitDelay = waitDelay
+
+	return cmd
+}
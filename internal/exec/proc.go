@@ -0,0 +1,38 @@
+//go:build !windows
+
+package exec
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+)
+
+// isolate puts the process of cmd in a process group of its own, so that it and every process it
+// starts can be killed together.
+func isolate(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func killGroup(p *os.Process) error {
+	return syscall.Kill(-p.Pid, syscall.SIGKILL)
+}
+
+func usage(state *os.ProcessState) Usage {
+	if state == nil {
+		return Usage{}
+	}
+	rusage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return Usage{}
+	}
+
+	// Darwin reports the peak in bytes, the others in kilobytes.
+	rss := uint64(rusage.Maxrss)
+	if runtime.GOOS != "darwin" {
+		rss *= 1024
+	}
+
+	return Usage{MaxRSS: rss}
+}
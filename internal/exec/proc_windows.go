@@ -0,0 +1,21 @@
+package exec
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// isolate puts the process of cmd in a process group of its own.
+func isolate(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+func killGroup(p *os.Process) error {
+	return p.Kill()
+}
+
+// usage is unknown on Windows, where the OS doesn't keep track of the peak memory of a process.
+func usage(*os.ProcessState) Usage {
+	return Usage{}
+}
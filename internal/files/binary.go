@@ -0,0 +1,6 @@
+//go:build !windows
+
+package files
+
+// Binary is the name of a compiled runner.
+const Binary = "runner"
@@ -0,0 +1,4 @@
+package files
+
+// Binary is the name of a compiled runner.
+const Binary = "runner.exe"
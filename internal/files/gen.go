@@ -7,7 +7,7 @@ import (
 	"text/template"
 )
 
-func Gen(structure map[string]string, data map[string]string) error {
+func Gen(structure map[string]string, data any) error {
 	for fPath, tmpl := range structure {
 		if _, err := os.Stat(fPath); err == nil {
 			fmt.Printf("skipping %s, already exists\n", fPath)
@@ -34,7 +34,7 @@ func Gen(structure map[string]string, data map[string]string) error {
 	return nil
 }
 
-func GenTemp(files map[string]string, data map[string]string) (map[string]string, error) {
+func GenTemp(files map[string]string, data any) (map[string]string, error) {
 	tempFiles := make(map[string]string)
 
 	for fName, tmpl := range files {
@@ -12,6 +12,20 @@ const (
 	Dur     = "dur"
 	Session = "session"
 	LastRun = "lastrun"
+	Report  = "report"
+	Hash    = "hash"
+	Bench   = "bench"
+	Alloc   = "alloc"
+	RSS     = "rss"
+	Input   = "input"
+
+	LastCheck = "lastcheck"
+	History   = "history"
+	Guesses   = "guesses"
+
+	CPUProfile = "cpu.pprof"
+	MemProfile = "mem.pprof"
+	Trace      = "trace.out"
 )
 
 func ReadAll(files map[string]string) (map[string]string, error) {
@@ -0,0 +1,72 @@
+// Package watch notices changes to files by polling them. Polling behaves the same on every
+// platform and with every editor, however it chooses to save files.
+package watch
+
+import (
+	"context"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"time"
+)
+
+type stamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Changes polls the files at paths, and every file below those of them that are directories, every
+// interval. Once a change has been followed by quiet long enough, a value is sent on the returned
+// channel. Changes that arrive while a value is already waiting to be received are merged into it.
+// The channel is closed when ctx is done. Paths that don't exist are watched for being created.
+func Changes(ctx context.Context, interval, quiet time.Duration, paths ...string) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		last := snapshot(paths)
+		var changed time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if cur := snapshot(paths); !maps.Equal(cur, last) {
+					last, changed = cur, now
+					continue
+				}
+				if changed.IsZero() || now.Sub(changed) < quiet {
+					continue
+				}
+				changed = time.Time{}
+				select {
+				case ch <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	return ch
+}
+
+func snapshot(paths []string) map[string]stamp {
+	stamps := make(map[string]stamp)
+	for _, root := range paths {
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				stamps[path] = stamp{modTime: info.ModTime(), size: info.Size()}
+			}
+			return nil
+		})
+	}
+
+	return stamps
+}
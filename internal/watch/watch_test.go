@@ -0,0 +1,52 @@
+package watch_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gombrii/aoc/internal/watch"
+)
+
+func TestChanges(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "part1.go")
+	if err := os.WriteFile(file, []byte("package day1"), 0644); err != nil {
+		t.Fatalf("writing file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := watch.Changes(ctx, 10*time.Millisecond, 50*time.Millisecond, dir)
+
+	select {
+	case <-changes:
+		t.Fatal("change reported before any file changed")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// A burst of saves is reported once.
+	for i := range 3 {
+		if err := os.WriteFile(file, []byte("package day1\n"+string(rune('a'+i))), 0644); err != nil {
+			t.Fatalf("writing file: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	select {
+	case <-changes:
+	case <-time.After(time.Second):
+		t.Fatal("change wasn't reported")
+	}
+
+	select {
+	case <-changes:
+		t.Error("burst of changes was reported more than once")
+	case <-time.After(150 * time.Millisecond):
+	}
+
+	cancel()
+	for range changes {
+	}
+}
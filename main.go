@@ -14,7 +14,7 @@ import (
 )
 
 func main() {
-	if err := app.Start(commands.Commands{}, os.Args[1:]...); err != nil {
+	if err := app.Start(&commands.Commands{}, os.Args[1:]...); err != nil {
 		if errors.Is(err, app.ErrInput) {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(2)